@@ -0,0 +1,14 @@
+//go:build headless
+
+// Building with go build -tags headless leaves out the window and raylib,
+// giving a binary that only runs -headless and -replay and needs no cgo.
+
+package main
+
+import "errors"
+
+// run stands in for the windowed demo in builds without raylib, which only
+// support -headless and -replay
+func run(int64, int, int, string) error {
+	return errors.New("built without a window, run with -headless or -replay")
+}
@@ -5,6 +5,8 @@ import "github.com/Salvadego/ECS/pkg/ecs"
 const (
 	PositionID ecs.ComponentID = 1 << iota
 	RenderableID
+	LocalPositionID
+	GlobalPositionID
 	Vector2ID
 	VelocityID
 )
@@ -12,6 +14,8 @@ const (
 func init() {
 	ecs.RegisterComponentType[*Position](PositionID)
 	ecs.RegisterComponentType[*Renderable](RenderableID)
+	ecs.RegisterComponentType[*LocalPosition](LocalPositionID)
+	ecs.RegisterComponentType[*GlobalPosition](GlobalPositionID)
 	ecs.RegisterComponentType[*Vector2](Vector2ID)
 	ecs.RegisterComponentType[*Velocity](VelocityID)
 }
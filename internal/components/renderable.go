@@ -1,15 +1,51 @@
 package components
 
 import (
+	"image/color"
+
 	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// Shape is the primitive a Renderable draws.
+//
+//ecs:notcomponent
+type Shape uint8
 
-	rl "github.com/gen2brain/raylib-go/raylib"
+const (
+	ShapePoint Shape = iota
+	ShapeRect
+	ShapeCircle
+	ShapeSprite
 )
 
+// Renderable draws an entity at its Position. Rectangles and sprite frames
+// are Width by Height and centered on the position, circles have a diameter
+// of Width. Rotation is in degrees, clockwise around the position. Sprites
+// draw Frame of the sheet at index Sheet of the systems.SpriteSheets
+// resource, tinted by Color. Entities are drawn by increasing Layer, then by
+// entity ID.
 type Renderable struct {
-	Color rl.Color
+	Color         color.RGBA
+	Shape         Shape
+	Width, Height float64
+	Rotation      float64
+	Layer         int32
+	Sheet         uint16
+	Frame         int32
 }
 
 func (c Renderable) ID() ecs.ComponentID {
 	return RenderableID
 }
+
+// Renderable gained shapes in version 1. The old layout is declared in init,
+// out of reach of gen_ids.go, which would take it for a component.
+func init() {
+	type renderableV0 struct {
+		Color color.RGBA
+	}
+	ecs.RegisterComponentVersion(RenderableID, 1)
+	ecs.RegisterMigration(RenderableID, 0, func(r renderableV0) Renderable {
+		return Renderable{Color: r.Color}
+	})
+}
@@ -0,0 +1,17 @@
+package components
+
+import "github.com/Salvadego/ECS/pkg/ecs"
+
+// LocalPosition is the position of an entity relative to its parent.
+type LocalPosition Vector2
+
+// GlobalPosition is the world position derived from LocalPosition.
+type GlobalPosition Vector2
+
+func (c LocalPosition) ID() ecs.ComponentID {
+	return LocalPositionID
+}
+
+func (c GlobalPosition) ID() ecs.ComponentID {
+	return GlobalPositionID
+}
@@ -5,9 +5,11 @@ import (
 	"github.com/Salvadego/ECS/pkg/ecs"
 )
 
-func Player(world *ecs.World) ecs.Entity {
-	player := world.NewEntity()
-	ecs.AddComponent(world, player, components.Position{X: 0, Y: 0})
-	ecs.AddComponent(world, player, components.Velocity{DX: 0, DY: 0})
-	return player
+var PlayerPrefab = ecs.NewPrefab("player",
+	&components.Position{X: 0, Y: 0},
+	&components.Velocity{X: 0, Y: 0},
+)
+
+func Player(world *ecs.World) ecs.EntityID {
+	return world.Instantiate(PlayerPrefab)
 }
@@ -0,0 +1,43 @@
+package render
+
+import "math"
+
+// Camera maps between world coordinates and screen pixels, like a raylib
+// Camera2D centered on the screen: the world point X, Y is shown at the
+// center of the screen, scaled by Zoom, and the world is drawn rotated by
+// Rotation degrees clockwise around it. A Zoom of 0 is taken as 1.
+type Camera struct {
+	X, Y     float64
+	Zoom     float64
+	Rotation float64
+}
+
+// NewCamera returns a camera showing the world 1:1 on a screen of the given
+// size, with the world origin at its top left corner.
+func NewCamera(width, height int) *Camera {
+	return &Camera{X: float64(width) / 2, Y: float64(height) / 2, Zoom: 1}
+}
+
+// Scale returns the number of screen pixels per world unit.
+func (c *Camera) Scale() float64 {
+	if c.Zoom == 0 {
+		return 1
+	}
+	return c.Zoom
+}
+
+// WorldToScreen returns where the world point x, y is on a screen of the
+// given size.
+func (c *Camera) WorldToScreen(x, y float64, width, height int) (float64, float64) {
+	sin, cos := math.Sincos(c.Rotation * math.Pi / 180)
+	dx, dy := (x-c.X)*c.Scale(), (y-c.Y)*c.Scale()
+	return dx*cos - dy*sin + float64(width)/2, dx*sin + dy*cos + float64(height)/2
+}
+
+// ScreenToWorld returns the world point shown at x, y on a screen of the
+// given size, the inverse of WorldToScreen.
+func (c *Camera) ScreenToWorld(x, y float64, width, height int) (float64, float64) {
+	sin, cos := math.Sincos(c.Rotation * math.Pi / 180)
+	dx, dy := x-float64(width)/2, y-float64(height)/2
+	return (dx*cos+dy*sin)/c.Scale() + c.X, (-dx*sin+dy*cos)/c.Scale() + c.Y
+}
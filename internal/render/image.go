@@ -0,0 +1,160 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Image is a Renderer drawing into an image.RGBA. It is double buffered:
+// drawing goes to a back buffer and Present copies it to the frame returned
+// by Frame, so the output is the same on every machine and can be compared
+// against golden images.
+//
+// Shapes are not antialiased: a pixel is drawn when its center is inside the
+// shape, and sprites are sampled at the nearest pixel.
+type Image struct {
+	back, front *image.RGBA
+}
+
+// NewImage returns a renderer drawing frames of the given size.
+func NewImage(width, height int) *Image {
+	r := &Image{}
+	r.Resize(width, height)
+	return r
+}
+
+// Resize changes the size of the frames, dropping what was drawn.
+func (r *Image) Resize(width, height int) {
+	r.back = image.NewRGBA(image.Rect(0, 0, width, height))
+	r.front = image.NewRGBA(image.Rect(0, 0, width, height))
+}
+
+// Frame returns the last presented frame. It is overwritten by the next
+// Present; copy it to keep it.
+func (r *Image) Frame() *image.RGBA {
+	return r.front
+}
+
+func (r *Image) Size() (width, height int) {
+	return r.back.Rect.Dx(), r.back.Rect.Dy()
+}
+
+func (r *Image) Present() {
+	copy(r.front.Pix, r.back.Pix)
+}
+
+func (r *Image) Clear(c color.RGBA) {
+	p := premultiply(c)
+	pix := r.back.Pix
+	for i := 0; i < len(pix); i += 4 {
+		pix[i], pix[i+1], pix[i+2], pix[i+3] = p.R, p.G, p.B, p.A
+	}
+}
+
+func (r *Image) DrawPoint(x, y float64, c color.RGBA) {
+	r.blend(int(math.Floor(x)), int(math.Floor(y)), premultiply(c))
+}
+
+func (r *Image) DrawRect(q Quad, c color.RGBA) {
+	p := premultiply(c)
+	r.fillQuad(q, func(_, _ float64) color.RGBA { return p })
+}
+
+func (r *Image) DrawCircle(x, y, radius float64, c color.RGBA) {
+	p := premultiply(c)
+	bounds := image.Rect(
+		int(math.Floor(x-radius)), int(math.Floor(y-radius)),
+		int(math.Ceil(x+radius)), int(math.Ceil(y+radius)),
+	).Intersect(r.back.Rect)
+
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		for px := bounds.Min.X; px < bounds.Max.X; px++ {
+			dx, dy := float64(px)+0.5-x, float64(py)+0.5-y
+			if dx*dx+dy*dy < radius*radius {
+				r.blend(px, py, p)
+			}
+		}
+	}
+}
+
+func (r *Image) DrawSprite(sprite *Sprite, src image.Rectangle, q Quad, tint color.RGBA) {
+	src = src.Intersect(sprite.Image.Rect)
+	if src.Empty() {
+		return
+	}
+	r.fillQuad(q, func(u, v float64) color.RGBA {
+		sx := src.Min.X + min(int(u*float64(src.Dx())), src.Dx()-1)
+		sy := src.Min.Y + min(int(v*float64(src.Dy())), src.Dy()-1)
+		return modulate(sprite.Image.RGBAAt(sx, sy), tint)
+	})
+}
+
+// fillQuad blends the pixels whose center is inside q with the premultiplied
+// color shade returns for them, given their position in the quad from 0, 0 at
+// its top left corner to 1, 1 at its bottom right corner
+func (r *Image) fillQuad(q Quad, shade func(u, v float64) color.RGBA) {
+	if q.Width <= 0 || q.Height <= 0 {
+		return
+	}
+	sin, cos := math.Sincos(q.Rotation * math.Pi / 180)
+	hw, hh := q.Width/2, q.Height/2
+
+	// Bounding box of the rotated corners
+	ex := math.Abs(hw*cos) + math.Abs(hh*sin)
+	ey := math.Abs(hw*sin) + math.Abs(hh*cos)
+	bounds := image.Rect(
+		int(math.Floor(q.X-ex)), int(math.Floor(q.Y-ey)),
+		int(math.Ceil(q.X+ex)), int(math.Ceil(q.Y+ey)),
+	).Intersect(r.back.Rect)
+
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		for px := bounds.Min.X; px < bounds.Max.X; px++ {
+			// Rotate the pixel center back into the frame of the quad
+			dx, dy := float64(px)+0.5-q.X, float64(py)+0.5-q.Y
+			lx := dx*cos + dy*sin + hw
+			ly := -dx*sin + dy*cos + hh
+			if lx < 0 || lx >= q.Width || ly < 0 || ly >= q.Height {
+				continue
+			}
+			r.blend(px, py, shade(lx/q.Width, ly/q.Height))
+		}
+	}
+}
+
+// blend draws a premultiplied color over the pixel at x, y
+func (r *Image) blend(x, y int, c color.RGBA) {
+	if !(image.Point{X: x, Y: y}).In(r.back.Rect) || c.A == 0 {
+		return
+	}
+	i := r.back.PixOffset(x, y)
+	pix := r.back.Pix[i : i+4 : i+4]
+	inv := 255 - uint32(c.A)
+	pix[0] = c.R + uint8(uint32(pix[0])*inv/255)
+	pix[1] = c.G + uint8(uint32(pix[1])*inv/255)
+	pix[2] = c.B + uint8(uint32(pix[2])*inv/255)
+	pix[3] = c.A + uint8(uint32(pix[3])*inv/255)
+}
+
+// premultiply converts a raylib style color to the premultiplied form
+// image.RGBA stores
+func premultiply(c color.RGBA) color.RGBA {
+	a := uint32(c.A)
+	return color.RGBA{
+		R: uint8(uint32(c.R) * a / 255),
+		G: uint8(uint32(c.G) * a / 255),
+		B: uint8(uint32(c.B) * a / 255),
+		A: c.A,
+	}
+}
+
+// modulate multiplies a premultiplied color by a tint
+func modulate(c, tint color.RGBA) color.RGBA {
+	t := premultiply(tint)
+	return color.RGBA{
+		R: uint8(uint32(c.R) * uint32(t.R) / 255),
+		G: uint8(uint32(c.G) * uint32(t.G) / 255),
+		B: uint8(uint32(c.B) * uint32(t.B) / 255),
+		A: uint8(uint32(c.A) * uint32(t.A) / 255),
+	}
+}
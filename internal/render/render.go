@@ -0,0 +1,91 @@
+// Package render defines the Renderer the RenderSystem draws with, and a
+// pure Go backend drawing into an image.
+package render
+
+import (
+	"image"
+	"image/color"
+)
+
+// Renderer draws the frames of the RenderSystem. Coordinates are in pixels
+// from the top left corner of the frame, and colors are not premultiplied,
+// like raylib colors: {255, 0, 0, 128} is a half transparent red. Drawing
+// blends over what is already drawn.
+//
+// The RenderSystem clears and draws; whoever owns the frame loop calls
+// Present once everything, such as a HUD, is drawn.
+type Renderer interface {
+	// Size returns the size of the frame in pixels.
+	Size() (width, height int)
+	Clear(c color.RGBA)
+	DrawPoint(x, y float64, c color.RGBA)
+	DrawRect(q Quad, c color.RGBA)
+	DrawCircle(x, y, radius float64, c color.RGBA)
+	// DrawSprite stretches the part src of a sprite over q, multiplying its
+	// pixels by tint.
+	DrawSprite(sprite *Sprite, src image.Rectangle, q Quad, tint color.RGBA)
+	Present()
+}
+
+// Quad is a Width by Height rectangle centered on X, Y and rotated by
+// Rotation degrees clockwise around its center.
+type Quad struct {
+	X, Y          float64
+	Width, Height float64
+	Rotation      float64
+}
+
+// Sprite is an image drawn with DrawSprite. Backends may keep what they build
+// from it, such as a texture, so its pixels must not change once it is drawn.
+type Sprite struct {
+	Image *image.RGBA
+}
+
+// NewSprite copies img into a sprite.
+func NewSprite(img image.Image) *Sprite {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	for y := range bounds.Dy() {
+		for x := range bounds.Dx() {
+			rgba.Set(x, y, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return &Sprite{Image: rgba}
+}
+
+// Bounds returns the rectangle of the whole sprite.
+func (s *Sprite) Bounds() image.Rectangle {
+	return s.Image.Bounds()
+}
+
+// SpriteSheet is a sprite cut into frames of the same size, numbered left to
+// right then top to bottom.
+type SpriteSheet struct {
+	Sprite                  *Sprite
+	FrameWidth, FrameHeight int
+}
+
+// NewSpriteSheet cuts a sprite into frames of the given size. Pixels past the
+// last full row or column of frames are not used.
+func NewSpriteSheet(sprite *Sprite, frameWidth, frameHeight int) *SpriteSheet {
+	return &SpriteSheet{Sprite: sprite, FrameWidth: frameWidth, FrameHeight: frameHeight}
+}
+
+// Frames returns the number of frames of the sheet.
+func (s *SpriteSheet) Frames() int {
+	bounds := s.Sprite.Bounds()
+	return (bounds.Dx() / s.FrameWidth) * (bounds.Dy() / s.FrameHeight)
+}
+
+// Frame returns the rectangle of frame i of the sprite. Frames wrap around, so
+// an animation can count frames up forever.
+func (s *SpriteSheet) Frame(i int) image.Rectangle {
+	columns := s.Sprite.Bounds().Dx() / s.FrameWidth
+	frames := s.Frames()
+	if frames == 0 {
+		return image.Rectangle{}
+	}
+	i = (i%frames + frames) % frames
+	x, y := i%columns*s.FrameWidth, i/columns*s.FrameHeight
+	return image.Rect(x, y, x+s.FrameWidth, y+s.FrameHeight)
+}
@@ -0,0 +1,54 @@
+package systems
+
+import (
+	"math"
+
+	"github.com/Salvadego/ECS/internal/input"
+	"github.com/Salvadego/ECS/internal/render"
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+const (
+	// panSpeed is how many screen pixels a second the arrow keys pan by
+	panSpeed = 300.0
+	// zoomStep is the zoom factor of one notch of the mouse wheel
+	zoomStep = 1.1
+	minZoom  = 0.1
+	maxZoom  = 10.0
+)
+
+// CameraSystem moves the render.Camera resource from the input.State of the
+// frame: the arrow keys pan along the screen and the mouse wheel zooms,
+// keeping the world point under the mouse in place. It does nothing until
+// both resources are set.
+type CameraSystem struct {
+	world *ecs.World
+}
+
+func NewCameraSystem(world *ecs.World) *CameraSystem {
+	return &CameraSystem{world: world}
+}
+
+func (cs *CameraSystem) Update(dt float64) {
+	in := ecs.GetResource[input.State](cs.world)
+	camera := ecs.GetResource[render.Camera](cs.world)
+	if in == nil || camera == nil {
+		return
+	}
+
+	if in.PanX != 0 || in.PanY != 0 {
+		// Pan in screen space so the keys follow the screen when rotated
+		sin, cos := math.Sincos(camera.Rotation * math.Pi / 180)
+		dx, dy := in.PanX*panSpeed*dt, in.PanY*panSpeed*dt
+		camera.X += (dx*cos + dy*sin) / camera.Scale()
+		camera.Y += (-dx*sin + dy*cos) / camera.Scale()
+	}
+
+	if in.Wheel != 0 {
+		x, y := camera.ScreenToWorld(in.MouseX, in.MouseY, in.Width, in.Height)
+		camera.Zoom = min(max(camera.Scale()*math.Pow(zoomStep, in.Wheel), minZoom), maxZoom)
+		nx, ny := camera.ScreenToWorld(in.MouseX, in.MouseY, in.Width, in.Height)
+		camera.X += x - nx
+		camera.Y += y - ny
+	}
+}
@@ -8,4 +8,5 @@ import (
 var (
 	velPosFilter  = ecs.NewFilter(components.PositionID, components.VelocityID)
 	posRendFilter = ecs.NewFilter(components.PositionID, components.RenderableID)
+	localFilter   = ecs.NewFilter(components.LocalPositionID)
 )
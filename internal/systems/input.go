@@ -4,34 +4,59 @@ import (
 	"math"
 
 	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/internal/input"
+	"github.com/Salvadego/ECS/internal/render"
 	"github.com/Salvadego/ECS/pkg/ecs"
-	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// Clicked is sent when the left mouse button is pressed, at the world
+// position of the mouse.
+type Clicked struct {
+	X, Y float64
+}
+
+// InputSystem steers every particle toward the mouse while the left button is
+// held. It reads the input.State resource of the frame, so it behaves the
+// same whether the input comes from the window or from a recording, and
+// converts the mouse to world coordinates through the render.Camera resource
+// when there is one.
 type InputSystem struct {
-	world *ecs.World
+	world  *ecs.World
+	clicks *ecs.Events[Clicked]
 }
 
 func NewInputSystem(world *ecs.World) *InputSystem {
 	return &InputSystem{
-		world: world,
+		world:  world,
+		clicks: ecs.GetEvents[Clicked](world),
 	}
 }
 
 func (is *InputSystem) Update(dt float64) {
-	if !rl.IsMouseButtonDown(rl.MouseButtonLeft) {
+	in := ecs.GetResource[input.State](is.world)
+	if in == nil {
 		return
 	}
 
-	for _, t := range velPosFilter.Query(is.world) {
+	mouseVector := components.Vector2{X: in.MouseX, Y: in.MouseY}
+	if camera := ecs.GetResource[render.Camera](is.world); camera != nil {
+		mouseVector.X, mouseVector.Y = camera.ScreenToWorld(in.MouseX, in.MouseY, in.Width, in.Height)
+	}
+
+	if in.MousePressed {
+		is.clicks.Send(Clicked{X: mouseVector.X, Y: mouseVector.Y})
+	}
+
+	if !in.MouseDown {
+		return
+	}
+
+	it := velPosFilter.Iterator(is.world)
+	for it.Next() {
+		t := it.Row()
 		pos := t[0].(*components.Position)
 		vel := t[1].(*components.Velocity)
 
-		mouseVector := components.Vector2{
-			X: float64(rl.GetMouseX()),
-			Y: float64(rl.GetMouseY()),
-		}
-
 		dir := components.Vector2{
 			X: mouseVector.X - pos.X,
 			Y: mouseVector.Y - pos.Y,
@@ -46,5 +71,6 @@ func (is *InputSystem) Update(dt float64) {
 		speed := 100.0
 		vel.X = dir.X * speed
 		vel.Y = dir.Y * speed
+		it.MarkChanged(1)
 	}
 }
@@ -23,18 +23,24 @@ func (ms *MovementSystem) SetSize(width, height int) {
 }
 
 func (ms *MovementSystem) Update(dt float64) {
-	for _, t := range velPosFilter.Query(ms.world) {
+	it := velPosFilter.Iterator(ms.world)
+	for it.Next() {
+		t := it.Row()
 		pos := t[0].(*components.Position)
 		vel := t[1].(*components.Velocity)
 
 		pos.X += vel.X * dt
 		pos.Y += vel.Y * dt
+		it.MarkChanged(0)
+
 		if pos.X <= 0 || pos.X >= float64(ms.screenWidth) {
 			vel.X *= -1
+			it.MarkChanged(1)
 		}
 
 		if pos.Y <= 0 || pos.Y >= float64(ms.screenHeight) {
 			vel.Y *= -1
+			it.MarkChanged(1)
 		}
 	}
 }
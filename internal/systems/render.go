@@ -1,65 +1,114 @@
 package systems
 
 import (
+	"cmp"
 	"image/color"
+	"math"
+	"slices"
 
 	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/internal/render"
 	"github.com/Salvadego/ECS/pkg/ecs"
-	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
+// SpriteSheets is the resource Renderable.Sheet indexes into.
+type SpriteSheets []*render.SpriteSheet
+
+// RenderSystem clears the frame and draws every renderable entity with a
+// render.Renderer, sorted by layer. Positions are in world coordinates, shown
+// through the render.Camera resource when there is one and 1:1 in screen
+// pixels otherwise; entities entirely off screen are skipped. It does not
+// present the frame, so more can be drawn on top of it.
 type RenderSystem struct {
-	world                     *ecs.World
-	screenWidth, screenHeight int
-	texture                   rl.Texture2D
-	framebuffer               []color.RGBA
+	world      *ecs.World
+	renderer   render.Renderer
+	items      []drawItem
+	Background color.RGBA
 }
 
-func NewRenderSystem(world *ecs.World, width, height int) *RenderSystem {
-	framebuffer := make([]color.RGBA, width*height)
-	image := rl.GenImageColor(width, height, rl.Black)
-	texture := rl.LoadTextureFromImage(image)
-	rl.UnloadImage(image)
+type drawItem struct {
+	entity ecs.EntityID
+	pos    *components.Position
+	rend   *components.Renderable
+}
 
+func NewRenderSystem(world *ecs.World, renderer render.Renderer) *RenderSystem {
 	return &RenderSystem{
-		world:        world,
-		screenWidth:  width,
-		screenHeight: height,
-		texture:      texture,
-		framebuffer:  framebuffer,
+		world:      world,
+		renderer:   renderer,
+		Background: color.RGBA{A: 255},
+	}
+}
+
+// Init and Shutdown forward to the renderer when it implements them
+func (rs *RenderSystem) Init() {
+	if init, ok := rs.renderer.(ecs.Initializer); ok {
+		init.Init()
 	}
 }
 
-func (rs *RenderSystem) SetSize(width, height int) {
-	rs.screenWidth, rs.screenHeight = width, height
-	rs.framebuffer = make([]color.RGBA, width*height)
-	image := rl.GenImageColor(width, height, rl.Black)
-	rs.texture = rl.LoadTextureFromImage(image)
-	rl.UnloadImage(image)
+func (rs *RenderSystem) Shutdown() {
+	if s, ok := rs.renderer.(ecs.Shutdowner); ok {
+		s.Shutdown()
+	}
 }
 
 func (rs *RenderSystem) Update(_ float64) {
-	for i := range rs.framebuffer {
-		rs.framebuffer[i] = color.RGBA{0, 0, 0, 255}
+	rs.renderer.Clear(rs.Background)
+
+	rs.items = rs.items[:0]
+	it := posRendFilter.Iterator(rs.world)
+	for it.Next() {
+		t := it.Row()
+		rs.items = append(rs.items, drawItem{
+			entity: it.Entity(),
+			pos:    t[0].(*components.Position),
+			rend:   t[1].(*components.Renderable),
+		})
 	}
+	slices.SortFunc(rs.items, func(a, b drawItem) int {
+		return cmp.Or(cmp.Compare(a.rend.Layer, b.rend.Layer), cmp.Compare(a.entity, b.entity))
+	})
 
-	for _, t := range posRendFilter.Query(rs.world) {
-		pos := t[0].(*components.Position)
-		rend := t[1].(components.Renderable)
+	var sheets SpriteSheets
+	if s := ecs.GetResource[SpriteSheets](rs.world); s != nil {
+		sheets = *s
+	}
+	camera := ecs.GetResource[render.Camera](rs.world)
+	width, height := rs.renderer.Size()
+	for _, item := range rs.items {
+		rs.draw(item.pos, item.rend, sheets, camera, width, height)
+	}
+	clear(rs.items)
+}
 
-		px := int(pos.X)
-		py := int(pos.Y)
-		if px >= 0 && px < rs.screenWidth && py >= 0 && py < rs.screenHeight {
-			i := py*rs.screenWidth + px
-			rs.framebuffer[i] = color.RGBA{
-				R: rend.Color.R,
-				G: rend.Color.G,
-				B: rend.Color.B,
-				A: rend.Color.A,
-			}
-		}
+func (rs *RenderSystem) draw(pos *components.Position, rend *components.Renderable, sheets SpriteSheets, camera *render.Camera, width, height int) {
+	quad := render.Quad{X: pos.X, Y: pos.Y, Width: rend.Width, Height: rend.Height, Rotation: rend.Rotation}
+	if camera != nil {
+		scale := camera.Scale()
+		quad.X, quad.Y = camera.WorldToScreen(pos.X, pos.Y, width, height)
+		quad.Width, quad.Height = rend.Width*scale, rend.Height*scale
+		quad.Rotation += camera.Rotation
 	}
 
-	rl.UpdateTexture(rs.texture, rs.framebuffer)
-	rl.DrawTexture(rs.texture, 0, 0, rl.White)
+	// Half the diagonal bounds every shape whatever its rotation
+	reach := max(math.Hypot(quad.Width, quad.Height)/2, 1)
+	if quad.X+reach < 0 || quad.Y+reach < 0 || quad.X-reach > float64(width) || quad.Y-reach > float64(height) {
+		return
+	}
+
+	switch rend.Shape {
+	case components.ShapePoint:
+		rs.renderer.DrawPoint(quad.X, quad.Y, rend.Color)
+	case components.ShapeRect:
+		rs.renderer.DrawRect(quad, rend.Color)
+	case components.ShapeCircle:
+		rs.renderer.DrawCircle(quad.X, quad.Y, quad.Width/2, rend.Color)
+	case components.ShapeSprite:
+		if int(rend.Sheet) >= len(sheets) || sheets[rend.Sheet] == nil {
+			return
+		}
+		sheet := sheets[rend.Sheet]
+		rs.renderer.DrawSprite(sheet.Sprite, sheet.Frame(int(rend.Frame)), quad, rend.Color)
+	}
 }
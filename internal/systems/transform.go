@@ -0,0 +1,131 @@
+package systems
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// TransformSystem derives GlobalPosition from LocalPosition along the ChildOf
+// hierarchy, parents first. Only subtrees whose LocalPosition, or whose root
+// parent's Position, changed since the last update are recomputed, along with
+// entities that got another parent or lost theirs. Children that also have a
+// Position get it overwritten with their global position so the other systems
+// follow them.
+type TransformSystem struct {
+	world *ecs.World
+	dirty map[ecs.EntityID]bool
+	done  map[ecs.EntityID]bool
+	// parents holds the parent each entity with a LocalPosition had at the
+	// previous update, roots are left out
+	parents, nextParents map[ecs.EntityID]ecs.EntityID
+}
+
+func NewTransformSystem(world *ecs.World) *TransformSystem {
+	return &TransformSystem{
+		world:       world,
+		dirty:       make(map[ecs.EntityID]bool),
+		done:        make(map[ecs.EntityID]bool),
+		parents:     make(map[ecs.EntityID]ecs.EntityID),
+		nextParents: make(map[ecs.EntityID]ecs.EntityID),
+	}
+}
+
+func (ts *TransformSystem) Update(_ float64) {
+	clear(ts.dirty)
+	clear(ts.done)
+	clear(ts.nextParents)
+
+	it := localFilter.Iterator(ts.world)
+	for it.Next() {
+		entity := it.Entity()
+		parent, ok := ts.world.Parent(entity)
+		if ok {
+			ts.nextParents[entity] = parent
+		}
+
+		switch old, had := ts.parents[entity]; {
+		case ok != had || old != parent:
+			ts.dirty[entity] = true
+		case ts.world.IsChanged(entity, components.LocalPositionID):
+			ts.dirty[entity] = true
+		case ok && !ts.hasLocal(parent) && ts.world.IsChanged(parent, components.PositionID):
+			ts.dirty[entity] = true
+		}
+	}
+	ts.parents, ts.nextParents = ts.nextParents, ts.parents
+
+	// Map order is random, walk the roots by ID so updates are deterministic
+	for _, entity := range slices.Sorted(maps.Keys(ts.dirty)) {
+		if ts.hasDirtyAncestor(entity) {
+			continue
+		}
+
+		var origin components.Vector2
+		if parent, ok := ts.world.Parent(entity); ok {
+			origin, _ = ts.globalPosition(parent)
+		}
+		ts.propagate(entity, origin)
+	}
+}
+
+// propagate recomputes the global position of entity and its descendants
+func (ts *TransformSystem) propagate(entity ecs.EntityID, origin components.Vector2) {
+	if ts.done[entity] {
+		return
+	}
+	ts.done[entity] = true
+
+	global := origin
+	if local := ecs.GetComponent[*components.LocalPosition](ts.world, entity); local != nil {
+		global.X += local.X
+		global.Y += local.Y
+	}
+
+	if gp := ecs.GetComponentMut[*components.GlobalPosition](ts.world, entity); gp != nil {
+		gp.X, gp.Y = global.X, global.Y
+	} else {
+		ts.world.AddComponents(entity, &components.GlobalPosition{X: global.X, Y: global.Y})
+	}
+
+	if pos := ecs.GetComponentMut[*components.Position](ts.world, entity); pos != nil {
+		pos.X, pos.Y = global.X, global.Y
+	}
+
+	for _, child := range ts.world.Children(entity) {
+		ts.propagate(child, global)
+	}
+}
+
+// globalPosition returns the world position of entity, preferring its
+// computed GlobalPosition over its Position
+func (ts *TransformSystem) globalPosition(entity ecs.EntityID) (components.Vector2, bool) {
+	if gp := ecs.GetComponent[*components.GlobalPosition](ts.world, entity); gp != nil {
+		return components.Vector2(*gp), true
+	}
+	if pos := ecs.GetComponent[*components.Position](ts.world, entity); pos != nil {
+		return components.Vector2(*pos), true
+	}
+	return components.Vector2{}, false
+}
+
+func (ts *TransformSystem) hasLocal(entity ecs.EntityID) bool {
+	return ecs.GetComponent[*components.LocalPosition](ts.world, entity) != nil
+}
+
+func (ts *TransformSystem) hasDirtyAncestor(entity ecs.EntityID) bool {
+	seen := map[ecs.EntityID]bool{entity: true}
+	for {
+		parent, ok := ts.world.Parent(entity)
+		if !ok || seen[parent] {
+			return false
+		}
+		if ts.dirty[parent] {
+			return true
+		}
+		seen[parent] = true
+		entity = parent
+	}
+}
@@ -0,0 +1,145 @@
+// Package window holds the raylib specific parts of the demo: the window,
+// a Renderer drawing into it and the input read from it.
+package window
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/Salvadego/ECS/internal/input"
+	"github.com/Salvadego/ECS/internal/render"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// Window is a render.Renderer drawing into the raylib window. The first draw
+// call of a frame begins drawing and Present ends it, so other raylib draw
+// calls may be made in between. Sprites are uploaded to textures the first
+// time they are drawn.
+type Window struct {
+	drawing  bool
+	textures map[*render.Sprite]rl.Texture2D
+}
+
+// Open opens a resizable window.
+func Open(width, height int, title string) *Window {
+	rl.SetConfigFlags(rl.FlagWindowResizable)
+	rl.InitWindow(int32(width), int32(height), title)
+	rl.SetTargetFPS(120)
+	return &Window{textures: make(map[*render.Sprite]rl.Texture2D)}
+}
+
+// ShouldClose reports whether the user asked to close the window.
+func (w *Window) ShouldClose() bool {
+	return rl.WindowShouldClose()
+}
+
+// Input reads the input of the current frame.
+func (w *Window) Input() input.State {
+	return input.State{
+		Dt:           float64(rl.GetFrameTime()),
+		Width:        rl.GetScreenWidth(),
+		Height:       rl.GetScreenHeight(),
+		MouseX:       float64(rl.GetMouseX()),
+		MouseY:       float64(rl.GetMouseY()),
+		MouseDown:    rl.IsMouseButtonDown(rl.MouseButtonLeft),
+		MousePressed: rl.IsMouseButtonPressed(rl.MouseButtonLeft),
+		Wheel:        float64(rl.GetMouseWheelMove()),
+		PanX:         axis(rl.KeyLeft, rl.KeyRight),
+		PanY:         axis(rl.KeyUp, rl.KeyDown),
+	}
+}
+
+// axis returns -1 while the negative key is held, 1 while the positive one
+// is, and 0 for neither or both
+func axis(negative, positive int32) float64 {
+	var v float64
+	if rl.IsKeyDown(negative) {
+		v--
+	}
+	if rl.IsKeyDown(positive) {
+		v++
+	}
+	return v
+}
+
+func (w *Window) Size() (width, height int) {
+	return rl.GetScreenWidth(), rl.GetScreenHeight()
+}
+
+// Begin begins drawing the frame if it was not yet begun.
+func (w *Window) Begin() {
+	if !w.drawing {
+		rl.BeginDrawing()
+		w.drawing = true
+	}
+}
+
+func (w *Window) Present() {
+	w.Begin()
+	rl.EndDrawing()
+	w.drawing = false
+}
+
+func (w *Window) Clear(c color.RGBA) {
+	w.Begin()
+	rl.ClearBackground(c)
+}
+
+func (w *Window) DrawPoint(x, y float64, c color.RGBA) {
+	w.Begin()
+	rl.DrawPixelV(rl.Vector2{X: float32(x), Y: float32(y)}, c)
+}
+
+func (w *Window) DrawRect(q render.Quad, c color.RGBA) {
+	w.Begin()
+	rl.DrawRectanglePro(destination(q), origin(q), float32(q.Rotation), c)
+}
+
+func (w *Window) DrawCircle(x, y, radius float64, c color.RGBA) {
+	w.Begin()
+	rl.DrawCircleV(rl.Vector2{X: float32(x), Y: float32(y)}, float32(radius), c)
+}
+
+func (w *Window) DrawSprite(sprite *render.Sprite, src image.Rectangle, q render.Quad, tint color.RGBA) {
+	w.Begin()
+	texture, ok := w.textures[sprite]
+	if !ok {
+		texture = rl.LoadTextureFromImage(rl.NewImageFromImage(sprite.Image))
+		w.textures[sprite] = texture
+	}
+	rl.DrawTexturePro(texture, rectangle(src), destination(q), origin(q), float32(q.Rotation), tint)
+}
+
+// Shutdown unloads the textures of the sprites; the RenderSystem calls it
+// when removed.
+func (w *Window) Shutdown() {
+	for sprite, texture := range w.textures {
+		rl.UnloadTexture(texture)
+		delete(w.textures, sprite)
+	}
+}
+
+// Close unloads the textures and closes the window.
+func (w *Window) Close() {
+	w.Shutdown()
+	rl.CloseWindow()
+}
+
+// destination and origin place the center of a quad at its position, the
+// point raylib rotates around
+func destination(q render.Quad) rl.Rectangle {
+	return rl.Rectangle{X: float32(q.X), Y: float32(q.Y), Width: float32(q.Width), Height: float32(q.Height)}
+}
+
+func origin(q render.Quad) rl.Vector2 {
+	return rl.Vector2{X: float32(q.Width / 2), Y: float32(q.Height / 2)}
+}
+
+func rectangle(r image.Rectangle) rl.Rectangle {
+	return rl.Rectangle{
+		X:      float32(r.Min.X),
+		Y:      float32(r.Min.Y),
+		Width:  float32(r.Dx()),
+		Height: float32(r.Dy()),
+	}
+}
@@ -1,14 +1,22 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
 	"math/rand"
+	"os"
+	"time"
 
 	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/internal/input"
+	"github.com/Salvadego/ECS/internal/render"
 	"github.com/Salvadego/ECS/internal/systems"
 	"github.com/Salvadego/ECS/pkg/ecs"
-	rl "github.com/gen2brain/raylib-go/raylib"
 )
 
 const (
@@ -16,66 +24,185 @@ const (
 	screenHeight = 450
 )
 
-var (
-	currWidth, currHeight = screenWidth, screenHeight
-	lastWidth, lastHeight = screenWidth, screenHeight
-)
-
 func main() {
 	entityCount := flag.Int64("n", 10000, "Entity count")
+	profileFrames := flag.Int("profile", 0, "Print per-system timings of the last N frames on exit")
+	seed := flag.Int64("seed", 0, "Seed of the particle spawner, 0 picks one from the clock")
+	recordFile := flag.String("record", "", "Record the input of every frame to this file")
+	replayFile := flag.String("replay", "", "Replay a recording without opening a window and print the final checksum")
+	headless := flag.Bool("headless", false, "Run the simulation without opening a window and print stats; build with -tags headless for a binary without raylib")
+	frames := flag.Int("frames", 600, "Number of frames to run in headless mode")
+	pngFile := flag.String("png", "", "Render the frames in headless mode and save the last one to this PNG file")
 	flag.Parse()
 
-	rl.SetConfigFlags(rl.FlagWindowResizable)
-	rl.InitWindow(screenWidth, screenHeight, "ECS")
-	rl.SetTargetFPS(120)
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	var err error
+	switch {
+	case *replayFile != "":
+		err = replay(*replayFile, *profileFrames)
+	case *headless:
+		err = runHeadless(*seed, int(*entityCount), *frames, *profileFrames, *pngFile)
+	default:
+		err = run(*seed, int(*entityCount), *profileFrames, *recordFile)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// spawnParticles creates the particles of the demo, drawing their positions,
+// velocities and alpha from rng so a seed always spawns the same particles
+func spawnParticles(world *ecs.World, rng *rand.Rand, n int) {
+	particle := ecs.NewPrefab("particle",
+		&components.Position{},
+		&components.Velocity{},
+		&components.Renderable{
+			Shape:  components.ShapeRect,
+			Width:  2,
+			Height: 2,
+			Color:  color.RGBA{R: 100, G: 255, B: 100, A: 255},
+		},
+	)
+	world.RegisterPrefab(particle)
+
+	world.InstantiateN(particle, n, func(_ int, row []ecs.Component) {
+		pos := row[0].(*components.Position)
+		pos.X = float64(rng.Intn(screenWidth))
+		pos.Y = float64(rng.Intn(screenHeight))
 
+		vel := row[1].(*components.Velocity)
+		vel.X = (rng.Float64()*10 - 1) * 10
+		vel.Y = (rng.Float64()*10 - 1) * 10
+
+		rend := row[2].(*components.Renderable)
+		rend.Color.A = uint8(rng.Intn(100) + 100)
+	})
+}
+
+// simulation is the demo world without rendering: the systems that only
+// depend on the input.State resource, so it runs the same with or without
+// a window. The camera starts showing the world 1:1 and is moved by input.
+type simulation struct {
+	world    *ecs.World
+	movement *systems.MovementSystem
+	state    *input.State
+}
+
+func newSimulation(seed int64, entities, profileFrames int) *simulation {
 	world := ecs.NewWorld()
-	movementSystem := systems.NewMovementSystem(world, screenWidth, screenHeight)
-	renderSystem := systems.NewRenderSystem(world, screenWidth, screenHeight)
-	inputSystem := systems.NewInputSystem(world)
-	world.AddSystems(movementSystem, renderSystem, inputSystem)
-
-	for range *entityCount {
-		world.CreateEntity(
-			&components.Position{
-				X: float64(rand.Intn(screenWidth)),
-				Y: float64(rand.Intn(screenHeight)),
-			},
-			&components.Velocity{
-				X: (rand.Float64()*10 - 1) * 10,
-				Y: (rand.Float64()*10 - 1) * 10,
-			},
-			components.Renderable{
-				// Width: 20,
-				// Height: 20,
-				Color: rl.Color{
-					R: 100,
-					G: 255,
-					B: 100,
-					A: uint8(rand.Intn(100) + 100),
-				},
-			},
-		)
+	movement := systems.NewMovementSystem(world, screenWidth, screenHeight)
+	world.AddSystems(
+		systems.NewCameraSystem(world),
+		movement,
+		systems.NewTransformSystem(world),
+		systems.NewInputSystem(world),
+	)
+	world.EnableProfiling(profileFrames)
+	spawnParticles(world, rand.New(rand.NewSource(seed)), entities)
+
+	state := &input.State{}
+	ecs.SetResource(world, state)
+	ecs.SetResource(world, render.NewCamera(screenWidth, screenHeight))
+	return &simulation{world: world, movement: movement, state: state}
+}
+
+// step runs one frame with the given input
+func (s *simulation) step(in input.State) {
+	*s.state = in
+	if in.Width > 0 && in.Height > 0 {
+		s.movement.SetSize(in.Width, in.Height)
+	}
+	s.world.Update(in.Dt)
+}
+
+// runHeadless runs the simulation for a number of frames at a fixed 60 FPS
+// timestep, with no input, and prints how long it took. When pngFile is set
+// the frames are rendered in memory and the last one is saved to it.
+func runHeadless(seed int64, entities, frames, profileFrames int, pngFile string) error {
+	sim := newSimulation(seed, entities, profileFrames)
+	defer sim.world.Close()
+
+	var renderer *render.Image
+	if pngFile != "" {
+		renderer = render.NewImage(screenWidth, screenHeight)
+		sim.world.AddSystems(systems.NewRenderSystem(sim.world, renderer))
 	}
 
-	for !rl.WindowShouldClose() {
-		currWidth, currHeight = rl.GetScreenWidth(), rl.GetScreenHeight()
-		if currWidth != lastWidth || currHeight != lastHeight {
-			movementSystem.SetSize(currWidth, currHeight)
-			renderSystem.SetSize(currWidth, currHeight)
+	in := input.State{Dt: 1.0 / 60, Width: screenWidth, Height: screenHeight}
+	start := time.Now()
+	for range frames {
+		sim.step(in)
+		if renderer != nil {
+			renderer.Present()
 		}
-		lastWidth, lastHeight = currWidth, currHeight
+	}
+	elapsed := time.Since(start)
+
+	avg := time.Duration(0)
+	if frames > 0 {
+		avg = elapsed / time.Duration(frames)
+	}
+	fmt.Printf("frames: %d, entities: %d, elapsed: %v, avg frame: %v, seed: %d, checksum: %016x\n",
+		frames, entities, elapsed.Round(time.Microsecond), avg, seed, sim.world.Checksum())
+	if profileFrames > 0 {
+		fmt.Print(sim.world.Stats())
+	}
+	if renderer != nil {
+		return writePNG(pngFile, renderer.Frame())
+	}
+	return nil
+}
 
-		rl.BeginDrawing()
-		rl.ClearBackground(rl.Black)
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
 
-		world.Update(float64(rl.GetFrameTime()))
+// replay runs the simulation systems over a recording, without rendering,
+// and prints the checksum of the final world, which matches the one the
+// recorded run printed
+func replay(path string, profileFrames int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
 
-		rl.DrawFPS(10, 10)
-		rl.DrawText(fmt.Sprintf("Entity count: %d", *entityCount), 10, 30, 20, rl.White)
+	player, err := input.NewPlayer(f)
+	if err != nil {
+		return err
+	}
 
-		rl.EndDrawing()
+	sim := newSimulation(player.Header.Seed, player.Header.Entities, profileFrames)
+	defer sim.world.Close()
+
+	frames := 0
+	for {
+		next, err := player.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		sim.step(next)
+		frames++
 	}
 
-	rl.CloseWindow()
+	fmt.Printf("frames: %d, seed: %d, checksum: %016x\n", frames, player.Header.Seed, sim.world.Checksum())
+	if profileFrames > 0 {
+		fmt.Print(sim.world.Stats())
+	}
+	return nil
 }
@@ -0,0 +1,96 @@
+package ecs
+
+import (
+	"fmt"
+	"slices"
+)
+
+// CreateEntities creates n entities made of the components ids, which must be
+// distinct and below PairIDBase. The entities are appended to their archetype
+// in one go, taking the world lock once. fill is called for each entity,
+// before the lock is taken, with a row holding one slot per id in the order
+// given; it must store a component with the matching ID in every slot.
+// CreateEntities panics, before creating anything, when an id is repeated or
+// a slot is left nil or holds another component. OnAdd hooks and observers
+// run once all entities exist.
+func (w *World) CreateEntities(n int, fill func(i int, row []Component), ids ...ComponentID) []EntityID {
+	for j, id := range ids {
+		if id >= PairIDBase {
+			panic(fmt.Sprintf("ecs: CreateEntities with ID %d, component IDs must be below PairIDBase (%d)", id, PairIDBase))
+		}
+		if slices.Contains(ids[:j], id) {
+			panic(fmt.Sprintf("ecs: CreateEntities with ID %d given twice", id))
+		}
+	}
+
+	columns := make([][]Component, len(ids))
+	for j := range columns {
+		columns[j] = make([]Component, n)
+	}
+
+	row := make([]Component, len(ids))
+	for i := range n {
+		clear(row)
+		fill(i, row)
+		for j, comp := range row {
+			if comp == nil {
+				panic(fmt.Sprintf("ecs: CreateEntities fill left slot %d of entity %d nil, want component %d", j, i, ids[j]))
+			}
+			if id := comp.ID(); id != ids[j] {
+				panic(fmt.Sprintf("ecs: CreateEntities fill stored %T with ID %d in slot %d of entity %d, want component %d", comp, id, j, i, ids[j]))
+			}
+			columns[j][i] = comp
+		}
+	}
+
+	signature := bitsOf(ids)
+	entities := make([]EntityID, n)
+
+	w.mu.Lock()
+	for i := range entities {
+		entities[i] = w.nextEntityID
+		w.nextEntityID++
+	}
+
+	archetype := w.getOrCreateArchetype(signature)
+	first := archetype.addEntities(entities, ids, columns, w.tick)
+	for i, entityID := range entities {
+		w.entityData[entityID] = EntityData{
+			archetype: archetype,
+			index:     first + i,
+		}
+	}
+	w.invalidateQueries()
+	w.mu.Unlock()
+
+	w.announceBatch(entities, signature, ids, columns)
+	return entities
+}
+
+// announceBatch fires OnAdd hooks and observers for entities created by
+// CreateEntities, skipping the work entirely when nothing listens
+func (w *World) announceBatch(entities []EntityID, signature BitSet, ids []ComponentID, columns [][]Component) {
+	hooked := false
+	for _, id := range ids {
+		if info, ok := componentTypes[id]; ok && info.hooks.OnAdd != nil {
+			hooked = true
+		}
+	}
+	observed := len(w.observersSnapshot()) > 0
+	if !hooked && !observed {
+		return
+	}
+
+	row := make([]Component, len(columns))
+	for i, entityID := range entities {
+		if hooked {
+			for j := range columns {
+				row[j] = columns[j][i]
+			}
+			w.runHooks(entityID, row, onAdd)
+		}
+		if observed {
+			w.fireEnters(entityID, nil, signature)
+		}
+	}
+}
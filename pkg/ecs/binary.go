@@ -0,0 +1,528 @@
+package ecs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"reflect"
+)
+
+// Binary snapshot layout, all integers little endian:
+//
+//	header     magic "ECSS", version uint16, next entity ID uint64
+//	schema     count uint32, then per component type: ID uint64, name
+//	           (uint16 length + bytes), schema version uint32, encoding
+//	           uint8, fixed size uint32
+//	archetypes count uint32, then per archetype: entity count uint32,
+//	           entity IDs, column count uint32 and the columns in ID order
+//	trailer    CRC-32 (IEEE) of everything before it
+//
+// A component column starts with columnComponent and the schema index of its
+// type, followed by every value packed back to back for fixed size types or
+// length prefixed for encoding.BinaryMarshaler types. A pair column starts
+// with columnPair, the relation and the target; every row holds the same pair.
+//
+// Version 1 snapshots have no schema version, their components are read as
+// version 0.
+const (
+	snapshotMagic   = "ECSS"
+	SnapshotVersion = 2
+)
+
+const (
+	columnComponent uint8 = iota
+	columnPair
+)
+
+// Component encodings
+const (
+	encodingFixed uint8 = iota
+	encodingMarshaler
+)
+
+// ErrChecksum is returned by ReadSnapshot when the snapshot is corrupted.
+var ErrChecksum = errors.New("ecs: snapshot checksum mismatch")
+
+// errCorrupted is returned for data that matches its checksum but does not
+// decode, which only a faulty writer produces
+var errCorrupted = errors.New("ecs: corrupted snapshot")
+
+// Smallest encoded size of the items snapshot counts are checked against
+const (
+	minSchemaEntrySize = 8 + 2 + 1 + 4
+	minArchetypeSize   = 4 + 4
+	minColumnSize      = 1 + 4
+)
+
+// schemaEntry describes how a component type is encoded in a snapshot
+type schemaEntry struct {
+	id       ComponentID
+	name     string
+	version  uint32
+	encoding uint8
+	size     uint32
+	typ      reflect.Type
+	info     *ComponentTypeInfo
+}
+
+// schemaFor returns how values of a registered component type are encoded
+func schemaFor(info *ComponentTypeInfo) (schemaEntry, error) {
+	entry, err := schemaOf(info.typeName, info.typ)
+	entry.id = info.id
+	entry.version = info.version
+	entry.info = info
+	return entry, err
+}
+
+// schemaOf returns how values of t are encoded: packed when t has a fixed
+// size, through encoding.BinaryMarshaler otherwise.
+func schemaOf(name string, t reflect.Type) (schemaEntry, error) {
+	entry := schemaEntry{name: name, typ: t}
+	elem := t
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+
+	if size := binary.Size(reflect.New(elem).Interface()); size >= 0 {
+		entry.encoding = encodingFixed
+		entry.size = uint32(size)
+		return entry, nil
+	}
+	if reflect.PointerTo(elem).Implements(reflect.TypeFor[encoding.BinaryUnmarshaler]()) &&
+		t.Implements(reflect.TypeFor[encoding.BinaryMarshaler]()) {
+		entry.encoding = encodingMarshaler
+		return entry, nil
+	}
+	return entry, fmt.Errorf("ecs: component %s has no fixed size and does not implement encoding.BinaryMarshaler", name)
+}
+
+// WriteSnapshot writes every entity of the world in the binary snapshot
+// format. Like MarshalJSON it leaves out systems, resources, events and change
+// ticks, and every component must be registered and stored as the registered
+// type. Components must either have a fixed size, as understood by
+// encoding/binary, or implement encoding.BinaryMarshaler and have their
+// pointer implement encoding.BinaryUnmarshaler.
+func (w *World) WriteSnapshot(wr io.Writer) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var archetypes []*Archetype
+	schema := make(map[ComponentID]int)
+	var entries []schemaEntry
+	for _, arch := range w.archetypes {
+		arch.mu.RLock()
+		if len(arch.entities) == 0 {
+			arch.mu.RUnlock()
+			continue
+		}
+		err := addSchemaEntries(arch, schema, &entries)
+		arch.mu.RUnlock()
+		if err != nil {
+			return err
+		}
+		archetypes = append(archetypes, arch)
+	}
+
+	crc := crc32.NewIEEE()
+	buf := bufio.NewWriter(io.MultiWriter(wr, crc))
+	enc := encoder{w: buf}
+
+	enc.bytes([]byte(snapshotMagic))
+	enc.uint16(SnapshotVersion)
+	enc.uint64(uint64(w.nextEntityID))
+
+	enc.uint32(uint32(len(entries)))
+	for _, entry := range entries {
+		enc.uint64(uint64(entry.id))
+		enc.uint16(uint16(len(entry.name)))
+		enc.bytes([]byte(entry.name))
+		enc.uint32(entry.version)
+		enc.uint8(entry.encoding)
+		enc.uint32(entry.size)
+	}
+
+	enc.uint32(uint32(len(archetypes)))
+	for _, arch := range archetypes {
+		arch.mu.RLock()
+		err := w.writeArchetype(&enc, arch, schema, entries)
+		arch.mu.RUnlock()
+		if err != nil {
+			return err
+		}
+	}
+	if enc.err != nil {
+		return enc.err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	return binary.Write(wr, binary.LittleEndian, crc.Sum32())
+}
+
+// addSchemaEntries appends the schema of the component types of arch that are
+// not in schema yet. The caller must hold arch.mu.
+func addSchemaEntries(arch *Archetype, schema map[ComponentID]int, entries *[]schemaEntry) error {
+	for _, slot := range arch.components {
+		if _, ok := schema[slot.id]; ok || slot.id >= PairIDBase {
+			continue
+		}
+		info, ok := componentTypes[slot.id]
+		if !ok || info.typ == nil {
+			return fmt.Errorf("ecs: component %d is not registered", slot.id)
+		}
+		entry, err := schemaFor(info)
+		if err != nil {
+			return err
+		}
+		schema[slot.id] = len(*entries)
+		*entries = append(*entries, entry)
+	}
+	return nil
+}
+
+// writeArchetype encodes the entities and columns of arch. The caller must
+// hold w.mu and arch.mu.
+func (w *World) writeArchetype(enc *encoder, arch *Archetype, schema map[ComponentID]int, entries []schemaEntry) error {
+	enc.uint32(uint32(len(arch.entities)))
+	for _, entity := range arch.entities {
+		enc.uint64(uint64(entity))
+	}
+
+	enc.uint32(uint32(len(arch.components)))
+	for _, slot := range arch.components {
+		if key, ok := w.relations.keys[slot.id]; ok {
+			enc.uint8(columnPair)
+			enc.uint32(uint32(key.relation))
+			enc.uint64(uint64(key.target))
+			continue
+		}
+		index, ok := schema[slot.id]
+		if !ok {
+			return fmt.Errorf("ecs: component %d has no schema entry", slot.id)
+		}
+		enc.uint8(columnComponent)
+		enc.uint32(uint32(index))
+		enc.column(entries[index], slot.data)
+	}
+	return nil
+}
+
+// ReadSnapshot creates a World from data written by World.WriteSnapshot.
+// Entities keep their IDs and archetypes, no hooks run and components count
+// as added on the first update. Components saved with an older schema version
+// are migrated. Data that does not match its checksum is reported as
+// ErrChecksum. The whole snapshot is read and checked before anything is
+// decoded, and every count is checked against the bytes left, so corrupted
+// data never drives an allocation.
+func ReadSnapshot(r io.Reader) (*World, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte(snapshotMagic)) {
+		return nil, errors.New("ecs: not a snapshot")
+	}
+	if len(data) < len(snapshotMagic)+4 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	payload, trailer := data[:len(data)-4], data[len(data)-4:]
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(trailer) {
+		return nil, ErrChecksum
+	}
+	dec := decoder{r: bytes.NewReader(payload[len(snapshotMagic):])}
+
+	format := dec.uint16()
+	if dec.err == nil && (format == 0 || format > SnapshotVersion) {
+		return nil, fmt.Errorf("ecs: unsupported snapshot version %d", format)
+	}
+
+	w := NewWorld()
+	w.nextEntityID = EntityID(dec.uint64())
+
+	entries := make([]schemaEntry, dec.count(minSchemaEntrySize))
+	for i := range entries {
+		if dec.err != nil {
+			break
+		}
+		entry := &entries[i]
+		entry.id = ComponentID(dec.uint64())
+		entry.name = string(dec.bytes(int(dec.uint16())))
+		if format >= 2 {
+			entry.version = dec.uint32()
+		}
+		entry.encoding = dec.uint8()
+		entry.size = dec.uint32()
+		if dec.err != nil {
+			break
+		}
+
+		id, ok := componentNames[entry.name]
+		if !ok {
+			return nil, fmt.Errorf("ecs: unknown component type %q", entry.name)
+		}
+		info := componentTypes[id]
+		t, err := info.decodeType(entry.version)
+		if err != nil {
+			return nil, fmt.Errorf("ecs: %w", err)
+		}
+		saved, err := schemaOf(entry.name, t)
+		if err != nil {
+			return nil, err
+		}
+		if saved.encoding != entry.encoding || saved.size != entry.size {
+			return nil, fmt.Errorf("ecs: component %s version %d does not match the snapshot schema", entry.name, entry.version)
+		}
+		entry.id = id
+		entry.typ = t
+		entry.info = info
+	}
+
+	for range dec.count(minArchetypeSize) {
+		if dec.err != nil {
+			break
+		}
+		entities := make([]EntityID, dec.count(8))
+		for i := range entities {
+			entities[i] = EntityID(dec.uint64())
+		}
+
+		columns := dec.count(minColumnSize)
+		ids := make([]ComponentID, 0, columns)
+		data := make([][]Component, 0, columns)
+		signature := BitSet{}
+		for range columns {
+			if dec.err != nil {
+				break
+			}
+			var id ComponentID
+			var column []Component
+			switch kind := dec.uint8(); kind {
+			case columnPair:
+				relation, target := RelationID(dec.uint32()), EntityID(dec.uint64())
+				id = w.pairID(relation, target)
+				column = make([]Component, len(entities))
+				for i := range column {
+					column[i] = Pair{id: id, Relation: relation, Target: target}
+				}
+			case columnComponent:
+				index := dec.uint32()
+				if int(index) >= len(entries) {
+					return nil, errCorrupted
+				}
+				id = entries[index].id
+				column = dec.column(entries[index], len(entities))
+			default:
+				return nil, errCorrupted
+			}
+			signature.Set(id)
+			ids = append(ids, id)
+			data = append(data, column)
+		}
+		if dec.err != nil {
+			break
+		}
+
+		for _, entity := range entities {
+			if _, ok := w.entityData[entity]; ok {
+				return nil, fmt.Errorf("ecs: duplicate entity %d", entity)
+			}
+			w.nextEntityID = max(w.nextEntityID, entity+1)
+		}
+		archetype := w.getOrCreateArchetype(signature)
+		first := archetype.addEntities(entities, ids, data, w.tick)
+		for i, entity := range entities {
+			w.entityData[entity] = EntityData{archetype: archetype, index: first + i}
+		}
+	}
+	if dec.err == nil && dec.r.Len() > 0 {
+		dec.err = errCorrupted
+	}
+	if dec.err != nil {
+		if dec.err == io.EOF {
+			dec.err = io.ErrUnexpectedEOF
+		}
+		return nil, dec.err
+	}
+	w.invalidateQueries()
+	return w, nil
+}
+
+// encoder writes snapshot values and keeps the first error
+type encoder struct {
+	w   io.Writer
+	err error
+	b   [8]byte
+}
+
+func (e *encoder) bytes(p []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(p)
+	}
+}
+
+func (e *encoder) uint8(v uint8) {
+	e.b[0] = v
+	e.bytes(e.b[:1])
+}
+
+func (e *encoder) uint16(v uint16) {
+	binary.LittleEndian.PutUint16(e.b[:], v)
+	e.bytes(e.b[:2])
+}
+
+func (e *encoder) uint32(v uint32) {
+	binary.LittleEndian.PutUint32(e.b[:], v)
+	e.bytes(e.b[:4])
+}
+
+func (e *encoder) uint64(v uint64) {
+	binary.LittleEndian.PutUint64(e.b[:], v)
+	e.bytes(e.b[:8])
+}
+
+// column writes the values of a component column
+func (e *encoder) column(entry schemaEntry, data []Component) {
+	if e.err != nil {
+		return
+	}
+	if entry.encoding == encodingMarshaler {
+		for _, comp := range data {
+			p, err := comp.(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				e.err = fmt.Errorf("ecs: encoding %s: %w", entry.name, err)
+				return
+			}
+			e.uint32(uint32(len(p)))
+			e.bytes(p)
+		}
+		return
+	}
+
+	pointer := entry.typ.Kind() == reflect.Pointer
+	elem := entry.typ
+	if pointer {
+		elem = elem.Elem()
+	}
+	values := reflect.MakeSlice(reflect.SliceOf(elem), len(data), len(data))
+	for i, comp := range data {
+		v := reflect.ValueOf(comp)
+		if v.Type() != entry.typ {
+			e.err = fmt.Errorf("ecs: component %s is stored as %s", entry.typ, v.Type())
+			return
+		}
+		if pointer {
+			v = v.Elem()
+		}
+		values.Index(i).Set(v)
+	}
+	e.err = binary.Write(e.w, binary.LittleEndian, values.Interface())
+}
+
+// decoder reads snapshot values and keeps the first error
+type decoder struct {
+	r   *bytes.Reader
+	err error
+	b   [8]byte
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err == nil && n > d.r.Len() {
+		d.err = io.ErrUnexpectedEOF
+	}
+	if d.err != nil {
+		return nil
+	}
+	p := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, p)
+	return p
+}
+
+func (d *decoder) fixed(n int) []byte {
+	if d.err != nil {
+		return d.b[:n]
+	}
+	_, d.err = io.ReadFull(d.r, d.b[:n])
+	return d.b[:n]
+}
+
+func (d *decoder) uint8() uint8   { return d.fixed(1)[0] }
+func (d *decoder) uint16() uint16 { return binary.LittleEndian.Uint16(d.fixed(2)) }
+func (d *decoder) uint32() uint32 { return binary.LittleEndian.Uint32(d.fixed(4)) }
+func (d *decoder) uint64() uint64 { return binary.LittleEndian.Uint64(d.fixed(8)) }
+
+// count reads the number of items that follow, each taking at least size
+// bytes, rejecting counts the bytes left cannot hold
+func (d *decoder) count(size int) int {
+	n := int(d.uint32())
+	if d.err == nil && n > d.r.Len()/size {
+		d.err = errCorrupted
+	}
+	if d.err != nil {
+		return 0
+	}
+	return n
+}
+
+// column reads n values of a component column. Pointer components of a fixed
+// size point into one array allocated for the whole column.
+func (d *decoder) column(entry schemaEntry, n int) []Component {
+	if d.err != nil {
+		return nil
+	}
+	pointer := entry.typ.Kind() == reflect.Pointer
+	elem := entry.typ
+	if pointer {
+		elem = elem.Elem()
+	}
+	column := make([]Component, n)
+
+	if entry.encoding == encodingMarshaler {
+		for i := range column {
+			p := d.bytes(int(d.uint32()))
+			if d.err != nil {
+				return nil
+			}
+			v := reflect.New(elem)
+			if err := v.Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(p); err != nil {
+				d.err = fmt.Errorf("ecs: decoding %s: %w", entry.name, err)
+				return nil
+			}
+			if !pointer {
+				v = v.Elem()
+			}
+			column[i] = d.component(entry, v)
+		}
+		return column
+	}
+
+	values := reflect.MakeSlice(reflect.SliceOf(elem), n, n)
+	if d.err = binary.Read(d.r, binary.LittleEndian, values.Interface()); d.err != nil {
+		return nil
+	}
+	for i := range column {
+		v := values.Index(i)
+		if pointer {
+			v = v.Addr()
+		}
+		column[i] = d.component(entry, v)
+	}
+	return column
+}
+
+// component returns a decoded value as a component, migrating it when it was
+// saved with an older schema version
+func (d *decoder) component(entry schemaEntry, v reflect.Value) Component {
+	if entry.version == entry.info.version {
+		return v.Interface().(Component)
+	}
+	comp, err := entry.info.upgrade(v.Interface(), entry.version)
+	if err != nil && d.err == nil {
+		d.err = fmt.Errorf("ecs: %w", err)
+	}
+	return comp
+}
@@ -0,0 +1,144 @@
+package ecs
+
+// componentTicks records when a component was attached to its entity and when
+// it was last modified, as values of the world change tick.
+type componentTicks struct {
+	added   uint64
+	changed uint64
+}
+
+// Changed restricts the filter to rows where at least one of the components
+// was added or marked changed since the running system last ran. Outside of
+// World.Update it compares against the end of the previous update. The
+// components must be present but are not part of the row.
+func (f *Filter) Changed(ids ...ComponentID) *Filter {
+	for _, id := range ids {
+		f.changed.Set(id)
+	}
+	return f
+}
+
+// Added restricts the filter to rows where at least one of the components was
+// attached since the running system last ran, like Changed.
+func (f *Filter) Added(ids ...ComponentID) *Filter {
+	for _, id := range ids {
+		f.added.Set(id)
+	}
+	return f
+}
+
+// required returns every component an archetype must have to match the filter
+func (f Filter) required() BitSet {
+	required := make(BitSet, 0, len(f.include))
+	for _, set := range []BitSet{f.include, f.changed, f.added} {
+		for _, id := range set.Indices() {
+			required.Set(id)
+		}
+	}
+	return required
+}
+
+// cacheable reports whether the rows of the filter do not depend on ticks
+func (f Filter) cacheable() bool {
+	return f.changed.empty() && f.added.empty()
+}
+
+// MarkChanged flags the given column of the current row as modified, so that
+// Changed filters of systems running later pick the row up. Missing optional
+// columns are ignored.
+func (qi *QueryIterator) MarkChanged(column int) {
+	if ticks := qi.tickArrays[column]; ticks != nil {
+		ticks[qi.currentEntity-1].changed = qi.tick
+	}
+}
+
+// rowChanged reports whether the row at index passes the Changed and Added
+// restrictions of the query
+func (qi *QueryIterator) rowChanged(index int) bool {
+	if len(qi.changedTicks) > 0 && !anyAfter(qi.changedTicks, index, qi.lastRun, false) {
+		return false
+	}
+	if len(qi.addedTicks) > 0 && !anyAfter(qi.addedTicks, index, qi.lastRun, true) {
+		return false
+	}
+	return true
+}
+
+func anyAfter(columns [][]componentTicks, index int, tick uint64, added bool) bool {
+	for _, ticks := range columns {
+		t := ticks[index].changed
+		if added {
+			t = ticks[index].added
+		}
+		if t > tick {
+			return true
+		}
+	}
+	return false
+}
+
+// columnTicks appends the tick columns of ids to dst. The caller must hold a.mu.
+func (a *Archetype) columnTicks(dst [][]componentTicks, ids []ComponentID) [][]componentTicks {
+	for _, id := range ids {
+		dst = append(dst, a.components[a.compIndex[id]].ticks)
+	}
+	return dst
+}
+
+// ticksMap returns the ticks of the entity stored at index keyed by component ID
+func (a *Archetype) ticksMap(index int) map[ComponentID]componentTicks {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	ticks := make(map[ComponentID]componentTicks, len(a.components)+1)
+	for _, slot := range a.components {
+		ticks[slot.id] = slot.ticks[index]
+	}
+	return ticks
+}
+
+// MarkChanged flags components of an entity as modified. Use it after
+// mutating components through pointers obtained outside of a QueryIterator.
+func (w *World) MarkChanged(entity EntityID, ids ...ComponentID) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	data, ok := w.entityData[entity]
+	if !ok {
+		return
+	}
+
+	data.archetype.mu.Lock()
+	defer data.archetype.mu.Unlock()
+	for _, id := range ids {
+		if idx, ok := data.archetype.compIndex[id]; ok {
+			data.archetype.components[idx].ticks[data.index].changed = w.tick
+		}
+	}
+}
+
+// IsChanged reports whether a component of an entity was added or marked
+// changed since the running system last ran, with the same rules as
+// Filter.Changed.
+func (w *World) IsChanged(entity EntityID, id ComponentID) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	data, ok := w.entityData[entity]
+	if !ok {
+		return false
+	}
+
+	data.archetype.mu.RLock()
+	defer data.archetype.mu.RUnlock()
+	idx, ok := data.archetype.compIndex[id]
+	return ok && data.archetype.components[idx].ticks[data.index].changed > w.lastRun
+}
+
+// GetComponentMut retrieves a component for an entity and flags it as
+// modified, for components that are mutated in place through a pointer.
+func GetComponentMut[T Component](w *World, entity EntityID) T {
+	c := GetComponent[T](w, entity)
+	w.MarkChanged(entity, componentID[T]())
+	return c
+}
@@ -0,0 +1,271 @@
+package ecs
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// ArchetypeChecksum is the checksum of the entities of one archetype, with
+// one checksum per column to tell which component diverged.
+type ArchetypeChecksum struct {
+	Entities int
+	Sum      uint64
+	Columns  []ColumnChecksum
+}
+
+// ColumnChecksum is the checksum of one component across an archetype. Name
+// is the registered type name, or the relation and target of pairs.
+type ColumnChecksum struct {
+	Component ComponentID
+	Name      string
+	Sum       uint64
+}
+
+// Checksum hashes every entity and component value of the world in a
+// canonical order, by entity ID then component ID, so two worlds holding the
+// same state have the same checksum however their archetypes and rows are
+// laid out. Pairs are hashed by relation and target rather than by their
+// world specific ID. Values are hashed field by field through reflection;
+// pointers are followed and maps hashed independently of their order. A
+// pointer, map or slice leading back to a value enclosing it is hashed as a
+// reference to that value, so cyclic data hashes like its structure.
+func (w *World) Checksum() uint64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	entities := make([]EntityID, 0, len(w.entityData))
+	for entity := range w.entityData {
+		entities = append(entities, entity)
+	}
+	slices.Sort(entities)
+
+	h := newHasher()
+	for _, entity := range entities {
+		data := w.entityData[entity]
+		data.archetype.mu.RLock()
+		row := canonicalRow(data.archetype.rowLocked(data.index))
+		data.archetype.mu.RUnlock()
+
+		h.uint64(uint64(entity))
+		h.uint64(uint64(len(row)))
+		for _, comp := range row {
+			h.component(comp)
+		}
+	}
+	return h.Sum64()
+}
+
+// ArchetypeChecksums returns the checksum of every non-empty archetype,
+// ordered by composition. Rows are hashed by entity ID like in Checksum, so
+// comparing the checksums of two worlds localises the archetype and component
+// where they diverge.
+func (w *World) ArchetypeChecksums() []ArchetypeChecksum {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	type keyed struct {
+		key []Component
+		sum ArchetypeChecksum
+	}
+	var archetypes []keyed
+	for _, arch := range w.archetypes {
+		arch.mu.RLock()
+		if len(arch.entities) == 0 {
+			arch.mu.RUnlock()
+			continue
+		}
+
+		order := make([]int, len(arch.entities))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortFunc(order, func(a, b int) int {
+			return cmp.Compare(arch.entities[a], arch.entities[b])
+		})
+
+		// Hash the columns in canonical order
+		columns := canonicalRow(arch.rowLocked(order[0]))
+		sum := ArchetypeChecksum{Entities: len(arch.entities)}
+		archetypeHash := newHasher()
+		for _, first := range columns {
+			slot := arch.components[arch.compIndex[first.ID()]]
+			h := newHasher()
+			for _, index := range order {
+				h.uint64(uint64(arch.entities[index]))
+				h.component(slot.data[index])
+			}
+			column := ColumnChecksum{Component: slot.id, Name: columnName(first), Sum: h.Sum64()}
+			archetypeHash.uint64(column.Sum)
+			sum.Columns = append(sum.Columns, column)
+		}
+		for _, index := range order {
+			archetypeHash.uint64(uint64(arch.entities[index]))
+		}
+		sum.Sum = archetypeHash.Sum64()
+		arch.mu.RUnlock()
+
+		archetypes = append(archetypes, keyed{key: columns, sum: sum})
+	}
+
+	slices.SortFunc(archetypes, func(a, b keyed) int {
+		return slices.CompareFunc(a.key, b.key, compareCanonical)
+	})
+	sums := make([]ArchetypeChecksum, len(archetypes))
+	for i, a := range archetypes {
+		sums[i] = a.sum
+	}
+	return sums
+}
+
+// canonicalRow orders a row by component ID, pairs last by relation then
+// target, since pair IDs depend on the order pairs were first used
+func canonicalRow(row []Component) []Component {
+	slices.SortFunc(row, compareCanonical)
+	return row
+}
+
+func compareCanonical(a, b Component) int {
+	pa, aPair := a.(Pair)
+	pb, bPair := b.(Pair)
+	switch {
+	case aPair && bPair:
+		return cmp.Or(cmp.Compare(pa.Relation, pb.Relation), cmp.Compare(pa.Target, pb.Target))
+	case aPair:
+		return 1
+	case bPair:
+		return -1
+	}
+	return cmp.Compare(a.ID(), b.ID())
+}
+
+func columnName(c Component) string {
+	if pair, ok := c.(Pair); ok {
+		return fmt.Sprintf("pair(%d, %d)", pair.Relation, pair.Target)
+	}
+	if info, ok := componentTypes[c.ID()]; ok && info.typeName != "" {
+		return info.typeName
+	}
+	return reflect.TypeOf(c).String()
+}
+
+// hasher feeds values to FNV-1a in a platform independent encoding
+type hasher struct {
+	hash.Hash64
+	b [8]byte
+	// path holds the pointers, maps and slices being hashed, outermost first
+	path []reference
+}
+
+// reference identifies what a pointer, map or slice points to
+type reference struct {
+	kind reflect.Kind
+	ptr  uintptr
+}
+
+// cycleMark starts the hash of a reference to an enclosing value
+const cycleMark = math.MaxUint64
+
+func newHasher() *hasher {
+	return &hasher{Hash64: fnv.New64a()}
+}
+
+func (h *hasher) uint64(v uint64) {
+	binary.LittleEndian.PutUint64(h.b[:], v)
+	h.Write(h.b[:])
+}
+
+// component hashes the identity and value of a component
+func (h *hasher) component(c Component) {
+	if pair, ok := c.(Pair); ok {
+		h.uint64(uint64(PairIDBase))
+		h.uint64(uint64(pair.Relation))
+		h.uint64(uint64(pair.Target))
+		return
+	}
+	h.uint64(uint64(c.ID()))
+	h.value(reflect.ValueOf(c))
+}
+
+// value hashes v, following references unless they lead back to a value
+// being hashed
+func (h *hasher) value(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		ref := reference{kind: v.Kind(), ptr: v.Pointer()}
+		if i := slices.Index(h.path, ref); i >= 0 {
+			h.uint64(cycleMark)
+			h.uint64(uint64(i))
+			return
+		}
+		h.path = append(h.path, ref)
+		h.walk(v)
+		h.path = h.path[:len(h.path)-1]
+		return
+	}
+	h.walk(v)
+}
+
+// walk hashes v, calling value for its elements
+func (h *hasher) walk(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			h.uint64(1)
+		} else {
+			h.uint64(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.uint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.uint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.uint64(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		h.uint64(math.Float64bits(real(v.Complex())))
+		h.uint64(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		h.uint64(uint64(v.Len()))
+		h.Write([]byte(v.String()))
+	case reflect.Array, reflect.Slice:
+		h.uint64(uint64(v.Len()))
+		for i := range v.Len() {
+			h.value(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			h.value(v.Field(i))
+		}
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			h.uint64(0)
+			return
+		}
+		h.uint64(1)
+		h.value(v.Elem())
+	case reflect.Map:
+		// Combine entries with a sum so the iteration order does not matter
+		var sum uint64
+		iter := v.MapRange()
+		for iter.Next() {
+			entry := newHasher()
+			entry.path = h.path
+			entry.value(iter.Key())
+			entry.value(iter.Value())
+			sum += entry.Sum64()
+		}
+		h.uint64(uint64(v.Len()))
+		h.uint64(sum)
+	default:
+		// Channels and functions have no value to compare
+		h.uint64(uint64(v.Kind()))
+	}
+}
@@ -0,0 +1,124 @@
+package ecs
+
+import "reflect"
+
+// Cloner is implemented by components holding references, such as slices or
+// maps, that must not be shared between an entity and its copies.
+type Cloner interface {
+	Clone() Component
+}
+
+// EntityMapper is implemented by components storing EntityIDs, so those IDs
+// can be translated when entities move to another World. mapping holds the
+// new ID of every entity moved in the same call.
+type EntityMapper interface {
+	MapEntities(mapping map[EntityID]EntityID) Component
+}
+
+// cloneComponent deep copies Cloner components and copies the value behind
+// other pointer components
+func cloneComponent(c Component) Component {
+	if cloner, ok := c.(Cloner); ok {
+		return cloner.Clone()
+	}
+
+	v := reflect.ValueOf(c)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return c
+	}
+	clone := reflect.New(v.Elem().Type())
+	clone.Elem().Set(v.Elem())
+	return clone.Interface().(Component)
+}
+
+// Clone creates a new entity with copies of the components of entity,
+// including its relationships. Children are not cloned.
+func (w *World) Clone(entity EntityID) (EntityID, bool) {
+	components, ok := w.Components(entity)
+	if !ok {
+		return 0, false
+	}
+	for i, comp := range components {
+		components[i] = cloneComponent(comp)
+	}
+	return w.CreateEntity(components...), true
+}
+
+// MoveEntity moves an entity to dst, see MoveEntities.
+func (w *World) MoveEntity(dst *World, entity EntityID) (EntityID, bool) {
+	mapping := w.MoveEntities(dst, entity)
+	id, ok := mapping[entity]
+	return id, ok
+}
+
+// MoveEntities moves entities to dst, where they get new IDs, and returns the
+// mapping from old to new IDs. Relationships between moved entities are
+// recreated in dst, others are dropped, and EntityMapper components are given
+// the mapping. All entities exist in dst before any is destroyed in w, so a
+// hierarchy moves as a whole when all of its entities are passed. An entity
+// passed more than once moves once.
+func (w *World) MoveEntities(dst *World, entities ...EntityID) map[EntityID]EntityID {
+	rows := make([][]Component, 0, len(entities))
+	moved := make([]EntityID, 0, len(entities))
+	seen := make(map[EntityID]bool, len(entities))
+	for _, entity := range entities {
+		if seen[entity] {
+			continue
+		}
+		seen[entity] = true
+		if components, ok := w.Components(entity); ok {
+			rows = append(rows, components)
+			moved = append(moved, entity)
+		}
+	}
+
+	dst.mu.Lock()
+	mapping := make(map[EntityID]EntityID, len(moved))
+	for _, entity := range moved {
+		mapping[entity] = dst.nextEntityID
+		dst.nextEntityID++
+	}
+
+	created := make([]spawned, 0, len(moved))
+	for i, entity := range moved {
+		components := make([]Component, 0, len(rows[i]))
+		for _, comp := range rows[i] {
+			switch c := comp.(type) {
+			case Pair:
+				target, ok := mapping[c.Target]
+				if !ok {
+					continue
+				}
+				comp = Pair{
+					id:       dst.pairID(c.Relation, target),
+					Relation: c.Relation,
+					Target:   target,
+				}
+			case EntityMapper:
+				comp = c.MapEntities(mapping)
+			}
+			components = append(components, comp)
+		}
+
+		signature := BitSet{}
+		componentMap := make(map[ComponentID]Component, len(components))
+		for _, comp := range components {
+			id := comp.ID()
+			signature.Set(id)
+			componentMap[id] = comp
+		}
+		dst.insertAt(mapping[entity], signature, componentMap)
+		created = append(created, spawned{
+			entity:     mapping[entity],
+			signature:  signature,
+			components: components,
+		})
+	}
+	dst.mu.Unlock()
+	dst.announce(created)
+
+	for _, entity := range moved {
+		w.DestroyEntity(entity)
+	}
+	return mapping
+}
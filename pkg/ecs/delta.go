@@ -0,0 +1,230 @@
+package ecs
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Snapshot is an in-memory copy of the entities of a World, taken with
+// World.Snapshot to later compute a Delta. The zero Snapshot is an empty world.
+type Snapshot struct {
+	nextEntity EntityID
+	entities   map[EntityID][]Component
+}
+
+// Delta is the difference between a Snapshot and a later state of a World.
+// Every list is ordered by entity ID.
+type Delta struct {
+	NextEntity EntityID
+	Created    []EntityState
+	Destroyed  []EntityID
+	Updated    []EntityUpdate
+}
+
+// EntityState is an entity with all of its components, ordered by ID.
+type EntityState struct {
+	Entity     EntityID
+	Components []Component
+}
+
+// EntityUpdate lists the components of an entity that were added or whose
+// value changed, in Set, and the components that were removed, in Removed.
+// Removed holds the values the components had; only their type matters.
+type EntityUpdate struct {
+	Entity  EntityID
+	Set     []Component
+	Removed []Component
+}
+
+// Empty reports whether the delta holds no change.
+func (d *Delta) Empty() bool {
+	return len(d.Created) == 0 && len(d.Destroyed) == 0 && len(d.Updated) == 0
+}
+
+// Snapshot copies every entity of the world, copying components like Clone.
+// When ids are given only those components are copied, and entities holding
+// none of them are left out.
+func (w *World) Snapshot(ids ...ComponentID) *Snapshot {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var only BitSet
+	for _, id := range ids {
+		only.Set(id)
+	}
+
+	s := &Snapshot{
+		nextEntity: w.nextEntityID,
+		entities:   make(map[EntityID][]Component, len(w.entityData)),
+	}
+	for _, arch := range w.archetypes {
+		if len(ids) > 0 && !arch.signature.Intersects(only) {
+			continue
+		}
+		arch.mu.RLock()
+		for index, entity := range arch.entities {
+			row := arch.rowLocked(index)
+			kept := row[:0]
+			for _, comp := range row {
+				if len(ids) == 0 || only.Has(comp.ID()) {
+					kept = append(kept, cloneComponent(comp))
+				}
+			}
+			s.entities[entity] = kept
+		}
+		arch.mu.RUnlock()
+	}
+	return s
+}
+
+// Diff returns what changed in w since prev was taken. Component values are
+// compared with reflect.DeepEqual, so components mutated in place through a
+// pointer are detected whether or not they were marked changed. Relationship
+// pairs are compared by relation and target, so the delta can be applied to
+// another World. A nil prev stands for an empty world.
+func Diff(prev *Snapshot, w *World) *Delta {
+	return DiffSnapshots(prev, w.Snapshot())
+}
+
+// DiffSnapshots returns what changed between two snapshots, like Diff. A nil
+// prev stands for an empty world.
+func DiffSnapshots(prev, current *Snapshot) *Delta {
+	if prev == nil {
+		prev = &Snapshot{}
+	}
+
+	d := &Delta{NextEntity: current.nextEntity}
+	for _, entity := range slices.Sorted(maps.Keys(current.entities)) {
+		row := current.entities[entity]
+		old, ok := prev.entities[entity]
+		if !ok {
+			d.Created = append(d.Created, EntityState{Entity: entity, Components: row})
+			continue
+		}
+		if update, changed := diffRow(entity, old, row); changed {
+			d.Updated = append(d.Updated, update)
+		}
+	}
+	for _, entity := range slices.Sorted(maps.Keys(prev.entities)) {
+		if _, ok := current.entities[entity]; !ok {
+			d.Destroyed = append(d.Destroyed, entity)
+		}
+	}
+	return d
+}
+
+// deltaKey identifies a component across worlds, where pair IDs differ
+type deltaKey struct {
+	id       ComponentID
+	relation RelationID
+	target   EntityID
+}
+
+func keyOf(c Component) deltaKey {
+	if pair, ok := c.(Pair); ok {
+		return deltaKey{id: PairIDBase, relation: pair.Relation, target: pair.Target}
+	}
+	return deltaKey{id: c.ID()}
+}
+
+// diffRow compares the components of an entity in two states
+func diffRow(entity EntityID, old, row []Component) (EntityUpdate, bool) {
+	update := EntityUpdate{Entity: entity}
+	before := make(map[deltaKey]Component, len(old))
+	for _, comp := range old {
+		before[keyOf(comp)] = comp
+	}
+
+	for _, comp := range row {
+		key := keyOf(comp)
+		prev, ok := before[key]
+		delete(before, key)
+		if !ok || !reflect.DeepEqual(prev, comp) {
+			update.Set = append(update.Set, comp)
+		}
+	}
+	for _, comp := range old {
+		if _, ok := before[keyOf(comp)]; ok {
+			update.Removed = append(update.Removed, comp)
+		}
+	}
+	return update, len(update.Set) > 0 || len(update.Removed) > 0
+}
+
+// ApplyDelta brings w to the state d was computed from, provided w was in the
+// state of the snapshot d was diffed against. Destroyed entities are destroyed
+// first, then created entities are added with their original IDs, then
+// updates are applied. Hooks and observers fire like for the equivalent
+// World calls. Components are copied, so d can be applied to several worlds.
+func (w *World) ApplyDelta(d *Delta) {
+	for _, entity := range d.Destroyed {
+		w.DestroyEntity(entity)
+	}
+
+	w.mu.Lock()
+	created := make([]spawned, 0, len(d.Created))
+	for _, state := range d.Created {
+		if _, ok := w.entityData[state.Entity]; ok {
+			continue
+		}
+		components := make([]Component, len(state.Components))
+		signature := BitSet{}
+		componentMap := make(map[ComponentID]Component, len(components))
+		for i, comp := range state.Components {
+			comp = w.localize(comp)
+			id := comp.ID()
+			components[i] = comp
+			signature.Set(id)
+			componentMap[id] = comp
+		}
+		w.insertAt(state.Entity, signature, componentMap)
+		created = append(created, spawned{
+			entity:     state.Entity,
+			signature:  signature,
+			components: components,
+		})
+	}
+	w.nextEntityID = max(w.nextEntityID, d.NextEntity)
+	w.mu.Unlock()
+	w.announce(created)
+
+	for _, update := range d.Updated {
+		if len(update.Removed) > 0 {
+			ids := make([]ComponentID, 0, len(update.Removed))
+			w.mu.RLock()
+			for _, comp := range update.Removed {
+				if pair, ok := comp.(Pair); ok {
+					id, ok := w.relations.ids[pairKey{relation: pair.Relation, target: pair.Target}]
+					if !ok {
+						continue
+					}
+					ids = append(ids, id)
+				} else {
+					ids = append(ids, comp.ID())
+				}
+			}
+			w.mu.RUnlock()
+			w.RemoveComponents(update.Entity, ids...)
+		}
+		if len(update.Set) > 0 {
+			components := make([]Component, len(update.Set))
+			w.mu.Lock()
+			for i, comp := range update.Set {
+				components[i] = w.localize(comp)
+			}
+			w.mu.Unlock()
+			w.AddComponents(update.Entity, components...)
+		}
+	}
+}
+
+// localize copies a component taken from another world, giving pairs the ID
+// of their relation and target in w. The caller must hold w.mu.
+func (w *World) localize(c Component) Component {
+	if pair, ok := c.(Pair); ok {
+		pair.id = w.pairID(pair.Relation, pair.Target)
+		return pair
+	}
+	return cloneComponent(c)
+}
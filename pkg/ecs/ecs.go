@@ -1,7 +1,12 @@
 package ecs
 
 import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -21,6 +26,18 @@ type System interface {
 	Update(dt float64)
 }
 
+// Initializer is implemented by systems that acquire resources (textures,
+// files, goroutines...) when they are added to a World.
+type Initializer interface {
+	Init()
+}
+
+// Shutdowner is implemented by systems that release resources when they are
+// removed from a World or when the World is closed.
+type Shutdowner interface {
+	Shutdown()
+}
+
 // BitSet represents a dynamic bitset for component composition.
 type BitSet []ComponentID
 
@@ -37,6 +54,14 @@ func (b *BitSet) Set(index ComponentID) {
 	(*b)[word] |= 1 << bit
 }
 
+// Clear clears the bit at the given index.
+func (b BitSet) Clear(index ComponentID) {
+	word, bit := int(index/64), uint(index%64)
+	if word < len(b) {
+		b[word] &^= 1 << bit
+	}
+}
+
 // Has checks if the bit at the given index is set.
 func (b BitSet) Has(index ComponentID) bool {
 	word, bit := int(index/64), uint(index%64)
@@ -92,6 +117,16 @@ func (b BitSet) Intersects(other BitSet) bool {
 	return false
 }
 
+// empty reports whether no bit is set
+func (b BitSet) empty() bool {
+	for _, word := range b {
+		if word != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // Hash generates a hash value for the BitSet.
 func (b BitSet) Hash() ComponentID {
 	var hash ComponentID
@@ -119,27 +154,77 @@ func (b BitSet) Indices() []ComponentID {
 
 // ComponentTypeInfo stores type information for a component type
 type ComponentTypeInfo struct {
-	id       ComponentID
-	size     uintptr
-	typeName string
-	pool     sync.Pool
-}
-
-var componentTypes = make(map[ComponentID]*ComponentTypeInfo)
+	id         ComponentID
+	size       uintptr
+	typeName   string
+	typ        reflect.Type
+	version    uint32
+	migrations map[uint32]migration
+	pool       sync.Pool
+	hooks      ComponentHooks
+}
+
+var (
+	componentTypes = make(map[ComponentID]*ComponentTypeInfo)
+	componentIDs   = make(map[reflect.Type]ComponentID)
+	componentNames = make(map[string]ComponentID)
+)
 
-// RegisterComponentType registers information about a component type
-func RegisterComponentType[T Component](id ComponentID) {
-	var zero T
-	size := unsafe.Sizeof(zero)
-	componentTypes[id] = &ComponentTypeInfo{
-		id:   id,
-		size: size,
+// componentType returns the type information for id, creating it if needed
+func componentType(id ComponentID) *ComponentTypeInfo {
+	if info, ok := componentTypes[id]; ok {
+		return info
+	}
+	info := &ComponentTypeInfo{
+		id: id,
 		pool: sync.Pool{
 			New: func() any {
 				return make([]Component, 0, 64)
 			},
 		},
 	}
+	componentTypes[id] = info
+	return info
+}
+
+// RegisterComponentType registers information about a component type. The
+// type name, such as "components.Position" for *components.Position, keys the
+// component in snapshots, which decode it back into a T. IDs from
+// PairIDBase up are reserved for relationship pairs and panic.
+func RegisterComponentType[T Component](id ComponentID) {
+	if id >= PairIDBase {
+		panic(fmt.Sprintf("ecs: %s registered with ID %d, component IDs must be below PairIDBase (%d)", typeName(reflect.TypeFor[T]()), id, PairIDBase))
+	}
+	var zero T
+	t := reflect.TypeFor[T]()
+	info := componentType(id)
+	info.size = unsafe.Sizeof(zero)
+	info.typ = t
+	info.typeName = typeName(t)
+	componentIDs[t] = id
+	componentNames[info.typeName] = id
+}
+
+// typeName returns the name of t without the pointer indirection
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.String()
+}
+
+// componentID returns the ID of component type T. Pointer types are resolved
+// through their registration so ID is never called on a nil pointer.
+func componentID[T Component]() ComponentID {
+	var zero T
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Pointer {
+		return zero.ID()
+	}
+	if id, ok := componentIDs[t]; ok {
+		return id
+	}
+	return reflect.New(t.Elem()).Interface().(T).ID()
 }
 
 // EntityData stores entity information
@@ -150,8 +235,9 @@ type EntityData struct {
 
 // ComponentSlot stores components of a single type
 type ComponentSlot struct {
-	id   ComponentID
-	data []Component
+	id    ComponentID
+	data  []Component
+	ticks []componentTicks
 }
 
 // Archetype represents a group of entities with the same component composition.
@@ -177,6 +263,12 @@ func (a *Archetype) GetComponentData(id ComponentID) ([]Component, bool) {
 
 // AddEntity adds an entity to this archetype
 func (a *Archetype) AddEntity(entityID EntityID, componentMap map[ComponentID]Component) int {
+	return a.addEntity(entityID, componentMap, nil, 0)
+}
+
+// addEntity adds an entity keeping the ticks found in ticks and stamping the
+// other components with tick
+func (a *Archetype) addEntity(entityID EntityID, componentMap map[ComponentID]Component, ticks map[ComponentID]componentTicks, tick uint64) int {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -186,17 +278,106 @@ func (a *Archetype) AddEntity(entityID EntityID, componentMap map[ComponentID]Co
 
 	for id, comp := range componentMap {
 		if idx, ok := a.compIndex[id]; ok {
-			a.components[idx].data = append(a.components[idx].data, comp)
+			t, ok := ticks[id]
+			if !ok {
+				t = componentTicks{added: tick, changed: tick}
+			}
+			slot := &a.components[idx]
+			slot.data = append(slot.data, comp)
+			slot.ticks = append(slot.ticks, t)
 		}
 	}
 
 	return index
 }
 
+// addEntities appends entities whose components are given column by column,
+// in the order of ids, stamping them with tick. It returns the index of the
+// first entity.
+func (a *Archetype) addEntities(entities []EntityID, ids []ComponentID, columns [][]Component, tick uint64) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	first := len(a.entities)
+	a.entities = append(a.entities, entities...)
+	for i, entityID := range entities {
+		a.entityIndex[entityID] = first + i
+	}
+
+	t := componentTicks{added: tick, changed: tick}
+	for j, id := range ids {
+		slot := &a.components[a.compIndex[id]]
+		slot.data = append(slot.data, columns[j]...)
+		slot.ticks = slices.Grow(slot.ticks, len(entities))
+		for range entities {
+			slot.ticks = append(slot.ticks, t)
+		}
+	}
+
+	return first
+}
+
+// removeEntity swap-removes the entity stored at index and returns the entity
+// that was moved into its place, if any.
+func (a *Archetype) removeEntity(index int) (EntityID, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	last := len(a.entities) - 1
+	delete(a.entityIndex, a.entities[index])
+
+	moved := a.entities[last]
+	a.entities[index] = moved
+	a.entities = a.entities[:last]
+
+	for i := range a.components {
+		slot := &a.components[i]
+		slot.data[index] = slot.data[last]
+		slot.data[last] = nil
+		slot.data = slot.data[:last]
+		slot.ticks[index] = slot.ticks[last]
+		slot.ticks = slot.ticks[:last]
+	}
+
+	if index == last {
+		return 0, false
+	}
+	a.entityIndex[moved] = index
+	return moved, true
+}
+
+// row returns the components of the entity stored at index, ordered by ID
+func (a *Archetype) row(index int) []Component {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.rowLocked(index)
+}
+
+// rowLocked is row for callers already holding a.mu
+func (a *Archetype) rowLocked(index int) []Component {
+	row := make([]Component, len(a.components))
+	for i, slot := range a.components {
+		row[i] = slot.data[index]
+	}
+	return row
+}
+
+// componentMap returns the components of the entity stored at index keyed by ID
+func (a *Archetype) componentMap(index int) map[ComponentID]Component {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	componentMap := make(map[ComponentID]Component, len(a.components)+1)
+	for _, slot := range a.components {
+		componentMap[slot.id] = slot.data[index]
+	}
+	return componentMap
+}
+
 // Query cache to avoid recreating similar queries
 type queryCache struct {
 	mu     sync.RWMutex
-	cache  map[ComponentID][][]Component
+	rows   [][]Component
 	filter Filter
 }
 
@@ -204,22 +385,38 @@ type queryCache struct {
 type World struct {
 	mu                    sync.RWMutex
 	archetypes            []*Archetype
-	archetypeMap          map[ComponentID]*Archetype
+	archetypeMap          map[ComponentID][]*Archetype
 	archetypesByComponent map[ComponentID][]*Archetype
 	entityData            map[EntityID]EntityData
 	nextEntityID          EntityID
 	systems               []System
-	queryCache            map[ComponentID]*queryCache
+	systemTicks           []uint64
+	systemsVersion        uint64
+	tick                  uint64
+	lastRun               uint64
+	queryCache            map[string]*queryCache
+	profiler              *profiler
+	observers             []*Observer
+	resources             map[reflect.Type]any
+	relations             relationIndex
+	prefabs               map[string]*Prefab
+	frame                 uint64
+	rollback              *rollback
 }
 
 // NewWorld creates a new World instance.
 func NewWorld() *World {
 	return &World{
 		entityData:            make(map[EntityID]EntityData, 1024),
-		archetypeMap:          make(map[ComponentID]*Archetype, 64),
+		archetypeMap:          make(map[ComponentID][]*Archetype, 64),
 		archetypesByComponent: make(map[ComponentID][]*Archetype, 32),
 		systems:               make([]System, 0, 16),
-		queryCache:            make(map[ComponentID]*queryCache),
+		systemTicks:           make([]uint64, 0, 16),
+		tick:                  1,
+		queryCache:            make(map[string]*queryCache),
+		resources:             make(map[reflect.Type]any),
+		relations:             newRelationIndex(),
+		prefabs:               make(map[string]*Prefab),
 	}
 }
 
@@ -227,27 +424,28 @@ func NewWorld() *World {
 func (w *World) registerArchetype(archetype *Archetype) {
 	w.archetypes = append(w.archetypes, archetype)
 	hash := archetype.signature.Hash()
-	w.archetypeMap[hash] = archetype
+	w.archetypeMap[hash] = append(w.archetypeMap[hash], archetype)
 
 	for id := range archetype.compIndex {
 		w.archetypesByComponent[id] = append(w.archetypesByComponent[id], archetype)
 	}
 }
 
-// getOrCreateArchetype gets an existing archetype or creates a new one if it doesn't exist
-func (w *World) getOrCreateArchetype(signature BitSet, componentMap map[ComponentID]Component) *Archetype {
-	hash := signature.Hash()
-	archetype, exists := w.archetypeMap[hash]
-
-	if exists && archetype.signature.Equals(signature) {
-		return archetype
+// getOrCreateArchetype gets an existing archetype or creates a new one if it
+// doesn't exist. Signatures may share a hash, such as a pair and the component
+// whose ID is the pair ID modulo 64, so every archetype of a hash is compared.
+func (w *World) getOrCreateArchetype(signature BitSet) *Archetype {
+	for _, archetype := range w.archetypeMap[signature.Hash()] {
+		if archetype.signature.Equals(signature) {
+			return archetype
+		}
 	}
 
-	compArray := make([]ComponentSlot, 0, len(componentMap))
-	compIndex := make(map[ComponentID]int, len(componentMap))
+	ids := signature.Indices()
+	compArray := make([]ComponentSlot, 0, len(ids))
+	compIndex := make(map[ComponentID]int, len(ids))
 
-	i := 0
-	for id := range componentMap {
+	for i, id := range ids {
 		compIndex[id] = i
 
 		var data []Component
@@ -262,10 +460,9 @@ func (w *World) getOrCreateArchetype(signature BitSet, componentMap map[Componen
 			id:   id,
 			data: data,
 		})
-		i++
 	}
 
-	archetype = &Archetype{
+	archetype := &Archetype{
 		signature:   signature,
 		components:  compArray,
 		compIndex:   compIndex,
@@ -276,36 +473,54 @@ func (w *World) getOrCreateArchetype(signature BitSet, componentMap map[Componen
 	return archetype
 }
 
-// CreateEntity creates a new entity with the given components.
+// CreateEntity creates a new entity with the given components. OnAdd hooks
+// fire in argument order once the entity holds all of its components. When
+// several components share an ID the last one is kept and OnAdd fires once.
 func (w *World) CreateEntity(components ...Component) EntityID {
+	components = lastByID(components)
 	signature := BitSet{}
 	componentMap := make(map[ComponentID]Component, len(components))
 	for _, comp := range components {
-		id := comp.ID()
+		id := checkedID(comp)
 		signature.Set(id)
 		componentMap[id] = comp
 	}
 
 	w.mu.Lock()
+	entityID := w.insert(signature, componentMap)
+	w.mu.Unlock()
+
+	w.runHooks(entityID, components, onAdd)
+	w.fireEnters(entityID, nil, signature)
+	return entityID
+}
+
+// insert stores a new entity in the archetype matching signature. The caller
+// must hold w.mu and fire hooks and observers once it is released.
+func (w *World) insert(signature BitSet, componentMap map[ComponentID]Component) EntityID {
 	entityID := w.nextEntityID
 	w.nextEntityID++
+	w.insertAt(entityID, signature, componentMap)
+	return entityID
+}
 
-	archetype := w.getOrCreateArchetype(signature, componentMap)
+// insertAt is insert for an entity ID that was already reserved
+func (w *World) insertAt(entityID EntityID, signature BitSet, componentMap map[ComponentID]Component) {
+	archetype := w.getOrCreateArchetype(signature)
 
-	index := archetype.AddEntity(entityID, componentMap)
+	index := archetype.addEntity(entityID, componentMap, nil, w.tick)
 
 	w.entityData[entityID] = EntityData{
 		archetype: archetype,
 		index:     index,
 	}
-
-	w.mu.Unlock()
-	return entityID
+	w.invalidateQueries()
 }
 
+// AddSystems appends systems to the world, calling Init on those
+// implementing Initializer in the order they are given.
 func (w *World) AddSystems(systems ...System) {
 	w.mu.Lock()
-	defer w.mu.Unlock()
 
 	if cap(w.systems)-len(w.systems) < len(systems) {
 		newSystems := make([]System, len(w.systems), len(w.systems)+len(systems))
@@ -315,18 +530,139 @@ func (w *World) AddSystems(systems ...System) {
 
 	for _, system := range systems {
 		w.systems = append(w.systems, system)
+		w.systemTicks = append(w.systemTicks, 0)
+	}
+	w.systemsVersion++
+	w.mu.Unlock()
+
+	// Init runs without the world lock so systems can create entities.
+	for _, system := range systems {
+		if init, ok := system.(Initializer); ok {
+			init.Init()
+		}
+	}
+}
+
+// RemoveSystems removes systems from the world, calling Shutdown on those
+// implementing Shutdowner. Systems that were never added are ignored, as are
+// systems of types that cannot be compared, such as structs holding a slice;
+// use pointers to remove those.
+func (w *World) RemoveSystems(systems ...System) {
+	w.mu.Lock()
+	removed := make([]System, 0, len(systems))
+	for _, system := range systems {
+		for i, s := range w.systems {
+			if sameSystem(s, system) {
+				w.systems = append(w.systems[:i:i], w.systems[i+1:]...)
+				w.systemTicks = append(w.systemTicks[:i:i], w.systemTicks[i+1:]...)
+				removed = append(removed, system)
+				break
+			}
+		}
+	}
+	w.systemsVersion++
+	w.mu.Unlock()
+
+	for _, system := range removed {
+		if s, ok := system.(Shutdowner); ok {
+			s.Shutdown()
+		}
+	}
+}
+
+// sameSystem compares two systems without panicking on values of types that
+// are not comparable
+func sameSystem(a, b System) bool {
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.ValueOf(a).Comparable() && a == b
+}
+
+// Close removes every system from the world, calling Shutdown in reverse
+// order of addition so later systems can still rely on earlier ones.
+func (w *World) Close() {
+	w.mu.Lock()
+	systems := w.systems
+	w.systems = make([]System, 0, 16)
+	w.systemTicks = make([]uint64, 0, 16)
+	w.systemsVersion++
+	w.mu.Unlock()
+
+	for i := len(systems) - 1; i >= 0; i-- {
+		if s, ok := systems[i].(Shutdowner); ok {
+			s.Shutdown()
+		}
 	}
 }
 
-// Update runs all systems
+// Update runs all systems, then advances the event queues by one frame
 func (w *World) Update(dt float64) {
 	w.mu.RLock()
 	systems := w.systems
+	version := w.systemsVersion
+	profiler := w.profiler
 	w.mu.RUnlock()
 
-	for _, system := range systems {
-		system.Update(dt)
+	if profiler != nil {
+		profiler.beginFrame(len(systems))
+	}
+	for i, system := range systems {
+		w.beginSystem(system, i, version)
+		if profiler == nil {
+			system.Update(dt)
+		} else {
+			start := profiler.beginSystem()
+			system.Update(dt)
+			profiler.endSystem(system, start)
+		}
+		w.endSystem(system, i, version)
+	}
+	if profiler != nil {
+		profiler.endFrame()
+	}
+
+	w.mu.Lock()
+	w.lastRun = w.tick
+	w.tick++
+	w.frame++
+	if w.rollback != nil {
+		w.rollback.capture(w)
+	}
+	w.mu.Unlock()
+
+	w.swapEvents()
+}
+
+// beginSystem advances the change tick before system, which was at index i
+// of w.systems when they were at version, and compares changes against the
+// tick it last ran at
+func (w *World) beginSystem(system System, i int, version uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tick++
+	w.lastRun = 0
+	if j := w.systemIndex(system, i, version); j >= 0 {
+		w.lastRun = w.systemTicks[j]
+	}
+}
+
+// endSystem records the tick system ran at. A system removed while it ran
+// keeps no tick.
+func (w *World) endSystem(system System, i int, version uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if j := w.systemIndex(system, i, version); j >= 0 {
+		w.systemTicks[j] = w.tick
+	}
+}
+
+// systemIndex returns the index of system in w.systems, given it was at i
+// when they were at version, or -1 if it was removed since. Systems of
+// uncomparable types can only be found while the version is unchanged. The
+// caller must hold w.mu.
+func (w *World) systemIndex(system System, i int, version uint64) int {
+	if w.systemsVersion == version {
+		return i
 	}
+	return slices.IndexFunc(w.systems, func(s System) bool { return sameSystem(s, system) })
 }
 
 // GetComponent retrieves a component for an entity
@@ -340,7 +676,7 @@ func GetComponent[T Component](w *World, entity EntityID) T {
 		return zero
 	}
 
-	id := zero.ID()
+	id := componentID[T]()
 
 	data.archetype.mu.RLock()
 	defer data.archetype.mu.RUnlock()
@@ -356,8 +692,12 @@ func GetComponent[T Component](w *World, entity EntityID) T {
 }
 
 type Filter struct {
-	include BitSet
-	exclude BitSet
+	include  BitSet
+	exclude  BitSet
+	optional BitSet
+	changed  BitSet
+	added    BitSet
+	terms    []Term
 }
 
 func NewFilter(include ...ComponentID) Filter {
@@ -375,105 +715,140 @@ func (f *Filter) Without(ids ...ComponentID) *Filter {
 	return f
 }
 
+// Optional adds columns for components an entity may lack. They come after
+// the included components in the row, ordered by ID, and are nil for
+// entities without them.
+func (f *Filter) Optional(ids ...ComponentID) *Filter {
+	for _, id := range ids {
+		f.optional.Set(id)
+	}
+	return f
+}
+
 // QueryIterator allows for efficient iteration over query results
 type QueryIterator struct {
 	archetypes       []*Archetype
 	includeIDs       []ComponentID
+	optionalIDs      []ComponentID
+	changedIDs       []ComponentID
+	addedIDs         []ComponentID
+	lastRun, tick    uint64
 	currentArchetype int
 	currentEntity    int
+	entities         []EntityID
 	componentArrays  [][]Component
+	tickArrays       [][]componentTicks
+	changedTicks     [][]componentTicks
+	addedTicks       [][]componentTicks
 	row              []Component
+	rows             *atomic.Int64
 }
 
 // Next advances to the next result, returns false when done
 func (qi *QueryIterator) Next() bool {
 	for qi.currentArchetype < len(qi.archetypes) {
-		arch := qi.archetypes[qi.currentArchetype]
-
-		if qi.componentArrays == nil {
-			arch.mu.RLock()
-			qi.componentArrays = make([][]Component, len(qi.includeIDs))
-			allPresent := true
-
-			for i, id := range qi.includeIDs {
-				if comps, ok := arch.GetComponentData(id); ok {
-					qi.componentArrays[i] = comps
-				} else {
-					allPresent = false
-					break
-				}
-			}
-
-			if !allPresent {
-				arch.mu.RUnlock()
-				qi.currentArchetype++
-				qi.currentEntity = 0
-				qi.componentArrays = nil
-				continue
-			}
-
-			entityCount := len(arch.entities)
-			arch.mu.RUnlock()
+		if qi.entities == nil && !qi.load(qi.archetypes[qi.currentArchetype]) {
+			qi.nextArchetype()
+			continue
+		}
 
-			if entityCount == 0 {
-				qi.currentArchetype++
-				qi.currentEntity = 0
-				qi.componentArrays = nil
-				continue
-			}
+		if qi.currentEntity >= len(qi.entities) {
+			qi.nextArchetype()
+			continue
 		}
 
-		if qi.currentEntity >= len(qi.componentArrays[0]) {
-			qi.currentArchetype++
-			qi.currentEntity = 0
-			qi.componentArrays = nil
+		index := qi.currentEntity
+		qi.currentEntity++
+
+		if !qi.rowChanged(index) {
 			continue
 		}
 
 		if qi.row == nil {
-			qi.row = make([]Component, len(qi.includeIDs))
+			qi.row = make([]Component, len(qi.componentArrays))
 		}
-
-		valid := true
 		for i, comps := range qi.componentArrays {
-			if qi.currentEntity < len(comps) {
-				qi.row[i] = comps[qi.currentEntity]
-			} else {
-				valid = false
-				break
+			if comps == nil {
+				qi.row[i] = nil
+				continue
 			}
+			qi.row[i] = comps[index]
 		}
 
-		qi.currentEntity++
-
-		if valid {
-			return true
+		if qi.rows != nil {
+			qi.rows.Add(1)
 		}
+		return true
 	}
 
 	return false
 }
 
+// load captures the columns of arch, reporting false if it has no entities
+func (qi *QueryIterator) load(arch *Archetype) bool {
+	arch.mu.RLock()
+	defer arch.mu.RUnlock()
+
+	if len(arch.entities) == 0 {
+		return false
+	}
+
+	columns := len(qi.includeIDs) + len(qi.optionalIDs)
+	qi.componentArrays = make([][]Component, columns)
+	qi.tickArrays = make([][]componentTicks, columns)
+	for i, id := range qi.includeIDs {
+		idx, ok := arch.compIndex[id]
+		if !ok {
+			return false
+		}
+		qi.componentArrays[i] = arch.components[idx].data
+		qi.tickArrays[i] = arch.components[idx].ticks
+	}
+	for i, id := range qi.optionalIDs {
+		if idx, ok := arch.compIndex[id]; ok {
+			qi.componentArrays[len(qi.includeIDs)+i] = arch.components[idx].data
+			qi.tickArrays[len(qi.includeIDs)+i] = arch.components[idx].ticks
+		}
+	}
+
+	qi.changedTicks = arch.columnTicks(qi.changedTicks[:0], qi.changedIDs)
+	qi.addedTicks = arch.columnTicks(qi.addedTicks[:0], qi.addedIDs)
+	qi.entities = arch.entities
+	return true
+}
+
+func (qi *QueryIterator) nextArchetype() {
+	qi.currentArchetype++
+	qi.currentEntity = 0
+	qi.entities = nil
+}
+
 // Row returns the current result row
 func (qi *QueryIterator) Row() []Component {
 	return qi.row
 }
 
+// Entity returns the entity of the current result row
+func (qi *QueryIterator) Entity() EntityID {
+	return qi.entities[qi.currentEntity-1]
+}
+
 // Iterator returns an iterator for the query results
 func (f Filter) Iterator(w *World) *QueryIterator {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 
-	includeIDs := f.include.Indices()
-	if len(includeIDs) == 0 {
+	requiredIDs := f.required().Indices()
+	if len(requiredIDs) == 0 && f.optional.empty() && len(f.terms) == 0 {
 		return &QueryIterator{archetypes: []*Archetype{}}
 	}
 
-	// Find archetypes with fewest matching entities first
-	var candidateArchetypes []*Archetype
+	// Find archetypes with fewest matching entities first, falling back to
+	// every archetype when only optional components or terms are given
+	candidateArchetypes := w.archetypes
 	minCount := -1
 
-	for _, id := range includeIDs {
+	for _, id := range requiredIDs {
 		archetypes, exists := w.archetypesByComponent[id]
 		if !exists {
 			return &QueryIterator{archetypes: []*Archetype{}}
@@ -489,61 +864,110 @@ func (f Filter) Iterator(w *World) *QueryIterator {
 	matchingArchetypes := make([]*Archetype, 0, len(candidateArchetypes))
 
 	for _, arch := range candidateArchetypes {
-		if f.includeMatch(arch.signature) && !f.excludeMatch(arch.signature) {
+		if f.matches(arch.signature) {
 			matchingArchetypes = append(matchingArchetypes, arch)
 		}
 	}
 
-	return &QueryIterator{
-		archetypes: matchingArchetypes,
-		includeIDs: includeIDs,
+	it := &QueryIterator{
+		archetypes:  matchingArchetypes,
+		includeIDs:  f.include.Indices(),
+		optionalIDs: f.optional.Indices(),
+		changedIDs:  f.changed.Indices(),
+		addedIDs:    f.added.Indices(),
+		lastRun:     w.lastRun,
+		tick:        w.tick,
+	}
+	if w.profiler != nil {
+		it.rows = &w.profiler.rows
 	}
+	return it
 }
 
 // Query returns all matching component rows
 func (f Filter) Query(w *World) [][]Component {
+	if !f.cacheable() {
+		return f.collect(w)
+	}
+
 	w.mu.RLock()
 
-	cacheKey := f.include.Hash() ^ (f.exclude.Hash() << 1)
+	cacheKey := f.cacheKey()
 	if cache, ok := w.queryCache[cacheKey]; ok {
+		profiler := w.profiler
 		cache.mu.RLock()
 		w.mu.RUnlock()
-		result := cache.cache[cacheKey]
+		result := cache.rows
 		cache.mu.RUnlock()
 		if result != nil {
+			if profiler != nil {
+				profiler.rows.Add(int64(len(result)))
+			}
 			return result
 		}
 	}
 	w.mu.RUnlock()
 
-	it := f.Iterator(w)
-	result := make([][]Component, 0, 64)
-
-	for it.Next() {
-		row := make([]Component, len(it.row))
-		copy(row, it.row)
-		result = append(result, row)
-	}
+	result := f.collect(w)
 
 	w.mu.Lock()
 	if _, ok := w.queryCache[cacheKey]; !ok {
-		w.queryCache[cacheKey] = &queryCache{
-			cache:  make(map[ComponentID][][]Component),
-			filter: f,
-		}
+		w.queryCache[cacheKey] = &queryCache{filter: f}
 	}
 	cache := w.queryCache[cacheKey]
 	w.mu.Unlock()
 
 	cache.mu.Lock()
-	cache.cache[cacheKey] = result
+	cache.rows = result
 	cache.mu.Unlock()
 
 	return result
 }
 
+// cacheKey identifies the filter in the query cache. It holds the exact
+// words of the component sets, without trailing zero words since those
+// do not change what matches, and the encoding of the terms, so two filters
+// share a key only when they yield the same rows.
+func (f Filter) cacheKey() string {
+	var key []byte
+	for _, set := range []BitSet{f.include, f.exclude, f.optional} {
+		key = appendBits(key, set)
+	}
+	return string(appendTerms(key, f.terms))
+}
+
+// appendBits appends a length prefixed encoding of the words of a set
+func appendBits(key []byte, set BitSet) []byte {
+	n := len(set)
+	for n > 0 && set[n-1] == 0 {
+		n--
+	}
+	key = binary.AppendUvarint(key, uint64(n))
+	for _, word := range set[:n] {
+		key = binary.LittleEndian.AppendUint64(key, uint64(word))
+	}
+	return key
+}
+
+// collect copies every row yielded by the filter's iterator
+func (f Filter) collect(w *World) [][]Component {
+	it := f.Iterator(w)
+	result := make([][]Component, 0, 64)
+
+	for it.Next() {
+		row := make([]Component, len(it.row))
+		copy(row, it.row)
+		result = append(result, row)
+	}
+	return result
+}
+
+func (f Filter) matches(sig BitSet) bool {
+	return f.includeMatch(sig) && !f.excludeMatch(sig) && andTerm(f.terms).match(sig)
+}
+
 func (f Filter) includeMatch(sig BitSet) bool {
-	return sig.ContainsAll(f.include)
+	return sig.ContainsAll(f.include) && sig.ContainsAll(f.changed) && sig.ContainsAll(f.added)
 }
 
 func (f Filter) excludeMatch(sig BitSet) bool {
@@ -553,5 +977,13 @@ func (f Filter) excludeMatch(sig BitSet) bool {
 func (w *World) ClearQueryCache() {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	w.queryCache = make(map[ComponentID]*queryCache)
+	w.queryCache = make(map[string]*queryCache)
+}
+
+// invalidateQueries drops cached query results after a structural change.
+// The caller must hold w.mu.
+func (w *World) invalidateQueries() {
+	if len(w.queryCache) > 0 {
+		clear(w.queryCache)
+	}
 }
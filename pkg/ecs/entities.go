@@ -0,0 +1,241 @@
+package ecs
+
+import "slices"
+
+// Alive reports whether the entity exists in the world.
+func (w *World) Alive(entity EntityID) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.entityData[entity]
+	return ok
+}
+
+// Components returns the components of an entity ordered by ID.
+func (w *World) Components(entity EntityID) ([]Component, bool) {
+	data, ok := w.lookup(entity)
+	if !ok {
+		return nil, false
+	}
+	return data.archetype.row(data.index), true
+}
+
+// lookup returns where an entity is stored
+func (w *World) lookup(entity EntityID) (EntityData, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	data, ok := w.entityData[entity]
+	return data, ok
+}
+
+// DestroyEntity removes an entity and all of its components from the world,
+// then applies the cascade policy of every relationship targeting it. It
+// reports whether the entity existed.
+func (w *World) DestroyEntity(entity EntityID) bool {
+	data, ok := w.lookup(entity)
+	if !ok {
+		return false
+	}
+	w.runHooks(entity, data.archetype.row(data.index), onRemove)
+
+	// OnRemove hooks may have changed the entity, exits see what it holds now
+	if data, ok = w.lookup(entity); !ok {
+		return false
+	}
+	w.fireExits(entity, data.archetype.signature, nil)
+
+	w.mu.Lock()
+	data, ok = w.entityData[entity]
+	if !ok {
+		w.mu.Unlock()
+		return false
+	}
+	w.detach(data)
+	delete(w.entityData, entity)
+	w.invalidateQueries()
+	w.mu.Unlock()
+
+	w.cascade(entity)
+	return true
+}
+
+// AddComponents attaches components to an existing entity, moving it to the
+// archetype matching its new composition. Components whose ID the entity
+// already has replace the current value and fire OnSet instead of OnAdd.
+// When several components share an ID the last one is kept.
+func (w *World) AddComponents(entity EntityID, components ...Component) bool {
+	data, ok := w.lookup(entity)
+	if !ok {
+		return false
+	}
+	components = lastByID(components)
+	before := data.archetype.signature
+	after := slices.Clone(before)
+	for _, comp := range components {
+		after.Set(checkedID(comp))
+	}
+	w.fireExits(entity, before, after)
+
+	w.mu.Lock()
+	data, ok = w.entityData[entity]
+	if !ok {
+		w.mu.Unlock()
+		return false
+	}
+	// onExit callbacks may have changed the entity, enters start from what it
+	// holds now
+	before = data.archetype.signature
+
+	componentMap := data.archetype.componentMap(data.index)
+	ticks := data.archetype.ticksMap(data.index)
+	added := make([]Component, 0, len(components))
+	var replaced []Component
+	for _, comp := range components {
+		id := comp.ID()
+		if t, exists := ticks[id]; exists {
+			replaced = append(replaced, comp)
+			t.changed = w.tick
+			ticks[id] = t
+		} else {
+			added = append(added, comp)
+		}
+		componentMap[id] = comp
+	}
+	w.migrate(entity, data, componentMap, ticks)
+	after = w.entityData[entity].archetype.signature
+	w.mu.Unlock()
+
+	w.runHooks(entity, added, onAdd)
+	w.runHooks(entity, replaced, onSet)
+	w.fireEnters(entity, before, after)
+	return true
+}
+
+// SetComponent replaces the component with the same ID as c on an entity.
+// It reports false, without adding c, when the entity lacks that component.
+func (w *World) SetComponent(entity EntityID, c Component) bool {
+	id := c.ID()
+
+	w.mu.Lock()
+	data, ok := w.entityData[entity]
+	if !ok {
+		w.mu.Unlock()
+		return false
+	}
+
+	data.archetype.mu.Lock()
+	idx, ok := data.archetype.compIndex[id]
+	if ok {
+		data.archetype.components[idx].data[data.index] = c
+		data.archetype.components[idx].ticks[data.index].changed = w.tick
+	}
+	data.archetype.mu.Unlock()
+	w.invalidateQueries()
+	w.mu.Unlock()
+
+	if ok {
+		w.runHooks(entity, []Component{c}, onSet)
+	}
+	return ok
+}
+
+// RemoveComponents detaches components from an entity, moving it to the
+// archetype matching its new composition. IDs the entity lacks are ignored.
+func (w *World) RemoveComponents(entity EntityID, ids ...ComponentID) bool {
+	data, ok := w.lookup(entity)
+	if !ok {
+		return false
+	}
+	removed := make([]Component, 0, len(ids))
+	for _, comp := range data.archetype.row(data.index) {
+		if slices.Contains(ids, comp.ID()) {
+			removed = append(removed, comp)
+		}
+	}
+	w.runHooks(entity, removed, onRemove)
+
+	// OnRemove hooks may have changed the entity, exits see what it holds now
+	if data, ok = w.lookup(entity); !ok {
+		return false
+	}
+	before := data.archetype.signature
+	after := slices.Clone(before)
+	for _, id := range ids {
+		after.Clear(id)
+	}
+	w.fireExits(entity, before, after)
+
+	w.mu.Lock()
+	data, ok = w.entityData[entity]
+	if !ok {
+		w.mu.Unlock()
+		return false
+	}
+	before = data.archetype.signature
+	componentMap := data.archetype.componentMap(data.index)
+	for _, id := range ids {
+		delete(componentMap, id)
+	}
+	w.migrate(entity, data, componentMap, data.archetype.ticksMap(data.index))
+	after = w.entityData[entity].archetype.signature
+	w.mu.Unlock()
+
+	w.fireEnters(entity, before, after)
+	return true
+}
+
+// lastByID keeps one component per ID, the last one given, at the position
+// where the ID first appears
+func lastByID(components []Component) []Component {
+	unique := make([]Component, 0, len(components))
+	for _, comp := range components {
+		id := comp.ID()
+		if i := slices.IndexFunc(unique, func(c Component) bool { return c.ID() == id }); i >= 0 {
+			unique[i] = comp
+		} else {
+			unique = append(unique, comp)
+		}
+	}
+	return unique
+}
+
+// migrate stores componentMap as the new composition of an entity, moving it
+// to another archetype when its signature changes. Components missing from
+// ticks are stamped as added now. The caller must hold w.mu.
+func (w *World) migrate(entity EntityID, data EntityData, componentMap map[ComponentID]Component, ticks map[ComponentID]componentTicks) {
+	w.invalidateQueries()
+
+	signature := BitSet{}
+	for id := range componentMap {
+		signature.Set(id)
+	}
+
+	if signature.Equals(data.archetype.signature) {
+		data.archetype.mu.Lock()
+		for id, comp := range componentMap {
+			slot := &data.archetype.components[data.archetype.compIndex[id]]
+			slot.data[data.index] = comp
+			slot.ticks[data.index] = ticks[id]
+		}
+		data.archetype.mu.Unlock()
+		return
+	}
+
+	w.detach(data)
+	archetype := w.getOrCreateArchetype(signature)
+	index := archetype.addEntity(entity, componentMap, ticks, w.tick)
+	w.entityData[entity] = EntityData{
+		archetype: archetype,
+		index:     index,
+	}
+}
+
+// detach removes an entity from its archetype and fixes the index of the
+// entity swapped into its place. The caller must hold w.mu.
+func (w *World) detach(data EntityData) {
+	if moved, ok := data.archetype.removeEntity(data.index); ok {
+		w.entityData[moved] = EntityData{
+			archetype: data.archetype,
+			index:     data.index,
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package ecs
+
+import (
+	"reflect"
+	"sync"
+)
+
+// eventQueue is implemented by resources swapped at the end of World.Update
+type eventQueue interface {
+	swap()
+}
+
+// eventBuffer holds consecutive events, the first one having sequence number start
+type eventBuffer[T any] struct {
+	start  uint64
+	events []T
+}
+
+func (b *eventBuffer[T]) end() uint64 {
+	return b.start + uint64(len(b.events))
+}
+
+// Events is a double-buffered queue of events of type T stored as a world
+// resource. Events sent during a frame stay readable during that frame and
+// the next one, then are dropped when World.Update swaps the buffers.
+type Events[T any] struct {
+	mu    sync.Mutex
+	older eventBuffer[T]
+	newer eventBuffer[T]
+}
+
+// GetEvents returns the event queue for T, registering it as a resource on
+// first use.
+func GetEvents[T any](w *World) *Events[T] {
+	key := reflect.TypeFor[Events[T]]()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if r, ok := w.resources[key]; ok {
+		return r.(*Events[T])
+	}
+	events := &Events[T]{}
+	w.resources[key] = events
+	return events
+}
+
+// Send queues events for readers.
+func (e *Events[T]) Send(events ...T) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.newer.events = append(e.newer.events, events...)
+}
+
+// Reader returns a reader positioned at the oldest event still queued.
+func (e *Events[T]) Reader() *EventReader[T] {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return &EventReader[T]{events: e, cursor: e.older.start}
+}
+
+// swap drops the events of the previous frame and starts a new buffer
+func (e *Events[T]) swap() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	reuse := e.older.events[:0]
+	clear(e.older.events)
+	e.older = e.newer
+	e.newer = eventBuffer[T]{start: e.older.end(), events: reuse}
+}
+
+// EventReader keeps track of which events a single consumer, usually a
+// system, has already seen.
+type EventReader[T any] struct {
+	events *Events[T]
+	cursor uint64
+}
+
+// Read returns the events sent since the previous call. Events dropped before
+// the reader got to them are skipped.
+func (r *EventReader[T]) Read() []T {
+	e := r.events
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var result []T
+	for _, b := range []*eventBuffer[T]{&e.older, &e.newer} {
+		if r.cursor < b.start {
+			r.cursor = b.start
+		}
+		if r.cursor < b.end() {
+			result = append(result, b.events[r.cursor-b.start:]...)
+			r.cursor = b.end()
+		}
+	}
+	return result
+}
+
+// swapEvents advances every event queue of the world by one frame
+func (w *World) swapEvents() {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	for _, r := range w.resources {
+		if q, ok := r.(eventQueue); ok {
+			q.swap()
+		}
+	}
+}
@@ -46,6 +46,9 @@ func main() {
 			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
 				for _, spec := range gd.Specs {
 					ts := spec.(*ast.TypeSpec)
+					if notComponent(gd, ts) {
+						continue
+					}
 					// include struct types or aliases
 					switch ts.Type.(type) {
 					case *ast.StructType, *ast.Ident:
@@ -58,6 +61,14 @@ func main() {
 		return nil
 	})
 
+	// IDs are 1 << iota and must stay below ecs.PairIDBase (1 << 12), the
+	// first ID of relationship pairs, so at most 12 component types fit
+	const maxComponents = 12
+	if len(names) > maxComponents {
+		fmt.Printf("%d component types, IDs past 1 << %d collide with relationship pairs\n", len(names), maxComponents-1)
+		os.Exit(1)
+	}
+
 	// 3. Generate ecs_ids.go
 	var buf bytes.Buffer
 	buf.WriteString("package components\n\n")
@@ -79,6 +90,23 @@ func main() {
 	os.WriteFile(filepath.Join(compDir, "components.go"), buf.Bytes(), 0644)
 }
 
+// notComponent reports whether a type is marked with an //ecs:notcomponent
+// line in its doc comment, for helper types of the components package such
+// as enums, which must not get an ID
+func notComponent(gd *ast.GenDecl, ts *ast.TypeSpec) bool {
+	for _, doc := range []*ast.CommentGroup{gd.Doc, ts.Doc} {
+		if doc == nil {
+			continue
+		}
+		for _, c := range doc.List {
+			if strings.TrimSpace(c.Text) == "//ecs:notcomponent" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func findModulePath() string {
 	cwd, err := os.Getwd()
 	if err != nil {
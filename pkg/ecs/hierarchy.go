@@ -0,0 +1,37 @@
+package ecs
+
+// SetParent makes child a child of parent through the ChildOf relation,
+// replacing any previous parent. It reports false if either entity does not
+// exist or if parent is child itself.
+func (w *World) SetParent(child, parent EntityID) bool {
+	if child == parent || !w.Alive(parent) {
+		return false
+	}
+	for _, old := range w.Targets(child, ChildOf) {
+		if old != parent {
+			w.RemovePair(child, ChildOf, old)
+		}
+	}
+	return w.AddPair(child, ChildOf, parent)
+}
+
+// RemoveParent detaches child from its parent, making it a root.
+func (w *World) RemoveParent(child EntityID) {
+	for _, parent := range w.Targets(child, ChildOf) {
+		w.RemovePair(child, ChildOf, parent)
+	}
+}
+
+// Parent returns the parent of child, if it has one.
+func (w *World) Parent(child EntityID) (EntityID, bool) {
+	targets := w.Targets(child, ChildOf)
+	if len(targets) == 0 {
+		return 0, false
+	}
+	return targets[0], true
+}
+
+// Children returns the direct children of parent.
+func (w *World) Children(parent EntityID) []EntityID {
+	return w.Sources(ChildOf, parent)
+}
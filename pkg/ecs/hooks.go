@@ -0,0 +1,54 @@
+package ecs
+
+// HookFunc is called with the entity and component a lifecycle event refers to.
+type HookFunc func(w *World, entity EntityID, c Component)
+
+// ComponentHooks are callbacks fired when components of one type are attached
+// to, replaced on or detached from an entity, in any World.
+//
+// Ordering guarantees:
+//   - OnAdd fires after the component has been stored, so GetComponent already
+//     returns it. CreateEntity fires OnAdd in argument order once the entity
+//     holds all of its components; AddComponents does the same for the
+//     components it attached.
+//   - OnSet fires after a component replaced one with the same ID, through
+//     SetComponent or AddComponents.
+//   - OnRemove fires before the component is detached, so it can still be
+//     read. DestroyEntity fires OnRemove for every component of the entity in
+//     ascending ID order before the entity disappears.
+//   - Moving an entity to another archetype keeps the components that are not
+//     added or removed; no hooks fire for them.
+//
+// Hooks run without any world lock held and may freely create, modify or
+// destroy entities. Those changes are applied immediately, before the
+// remaining hooks of the same operation run. Observers of the operation are
+// notified from the composition the entity has when it is moved, so changes
+// made by OnRemove hooks are taken into account.
+type ComponentHooks struct {
+	OnAdd    HookFunc
+	OnSet    HookFunc
+	OnRemove HookFunc
+}
+
+// RegisterComponentHooks sets the lifecycle hooks of a component type. Like
+// RegisterComponentType it is meant to be called during initialization.
+func RegisterComponentHooks(id ComponentID, hooks ComponentHooks) {
+	componentType(id).hooks = hooks
+}
+
+func onAdd(h *ComponentHooks) HookFunc    { return h.OnAdd }
+func onSet(h *ComponentHooks) HookFunc    { return h.OnSet }
+func onRemove(h *ComponentHooks) HookFunc { return h.OnRemove }
+
+// runHooks calls the hook chosen by kind for each component that has one
+func (w *World) runHooks(entity EntityID, components []Component, kind func(*ComponentHooks) HookFunc) {
+	for _, c := range components {
+		info, ok := componentTypes[c.ID()]
+		if !ok {
+			continue
+		}
+		if hook := kind(&info.hooks); hook != nil {
+			hook(w, entity, c)
+		}
+	}
+}
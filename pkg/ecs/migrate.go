@@ -0,0 +1,80 @@
+package ecs
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// migration upgrades a value decoded with the layout of one schema version to
+// the layout of the next
+type migration struct {
+	from  reflect.Type
+	apply func(any) any
+}
+
+// RegisterComponentVersion sets the schema version written with a component
+// type in snapshots. Components start at version 0; bump the version whenever
+// the encoded layout changes, such as a renamed or retyped field, and register
+// a migration from the previous version.
+func RegisterComponentVersion(id ComponentID, version uint32) {
+	componentType(id).version = version
+}
+
+// RegisterMigration registers how to upgrade a component saved with schema
+// version from, which decodes as a From, to version from+1. Loading a
+// snapshot chains migrations up to the registered version, so the migration
+// from the previous version must return the registered component type or the
+// type it points to. Older layouts are kept around as plain types that only
+// need to decode, not implement Component.
+func RegisterMigration[From, To any](id ComponentID, from uint32, migrate func(From) To) {
+	info := componentType(id)
+	if info.migrations == nil {
+		info.migrations = make(map[uint32]migration)
+	}
+	info.migrations[from] = migration{
+		from:  reflect.TypeFor[From](),
+		apply: func(v any) any { return migrate(v.(From)) },
+	}
+}
+
+// decodeType returns the type data saved with the given schema version of a
+// component decodes into
+func (info *ComponentTypeInfo) decodeType(version uint32) (reflect.Type, error) {
+	switch {
+	case version == info.version:
+		return info.typ, nil
+	case version > info.version:
+		return nil, fmt.Errorf("%s was saved with version %d, newer than version %d", info.typeName, version, info.version)
+	}
+	m, ok := info.migrations[version]
+	if !ok {
+		return nil, fmt.Errorf("no migration for %s from version %d", info.typeName, version)
+	}
+	return m.from, nil
+}
+
+// upgrade migrates a value decoded with the given schema version to the
+// registered component type
+func (info *ComponentTypeInfo) upgrade(value any, version uint32) (Component, error) {
+	for v := version; v < info.version; v++ {
+		m, ok := info.migrations[v]
+		if !ok {
+			return nil, fmt.Errorf("no migration for %s from version %d", info.typeName, v)
+		}
+		if t := reflect.TypeOf(value); t != m.from {
+			return nil, fmt.Errorf("migration of %s from version %d expects %s, got %s", info.typeName, v, m.from, t)
+		}
+		value = m.apply(value)
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type() == info.typ:
+		return value.(Component), nil
+	case info.typ.Kind() == reflect.Pointer && v.Type() == info.typ.Elem():
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		return p.Interface().(Component), nil
+	}
+	return nil, fmt.Errorf("migrations of %s end with %s", info.typeName, v.Type())
+}
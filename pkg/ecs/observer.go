@@ -0,0 +1,91 @@
+package ecs
+
+// ObserverFunc is called with an entity that started or stopped matching a Filter.
+type ObserverFunc func(w *World, entity EntityID)
+
+// Observer reacts to entities starting or stopping to match a Filter.
+type Observer struct {
+	filter  Filter
+	onEnter ObserverFunc
+	onExit  ObserverFunc
+}
+
+// Observe calls onEnter whenever an entity starts matching filter, because it
+// was created or gained or lost components, and onExit whenever it stops
+// matching, because it was destroyed or its composition changed. Either
+// callback may be nil. Entities already matching are passed to onEnter before
+// Observe returns.
+//
+// onExit runs while the entity still has its old composition, after any
+// OnRemove hooks; onEnter runs once the entity has its new composition,
+// after any OnAdd and OnSet hooks. Callbacks run without any world lock held.
+func (w *World) Observe(filter Filter, onEnter, onExit ObserverFunc) *Observer {
+	o := &Observer{
+		filter:  filter,
+		onEnter: onEnter,
+		onExit:  onExit,
+	}
+
+	w.mu.Lock()
+	w.observers = append(w.observers, o)
+	var matching []EntityID
+	if onEnter != nil {
+		for _, arch := range w.archetypes {
+			if filter.matches(arch.signature) {
+				arch.mu.RLock()
+				matching = append(matching, arch.entities...)
+				arch.mu.RUnlock()
+			}
+		}
+	}
+	w.mu.Unlock()
+
+	for _, entity := range matching {
+		onEnter(w, entity)
+	}
+	return o
+}
+
+// Unobserve stops o from receiving further callbacks.
+func (w *World) Unobserve(o *Observer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, observer := range w.observers {
+		if observer == o {
+			w.observers = append(w.observers[:i:i], w.observers[i+1:]...)
+			return
+		}
+	}
+}
+
+// fireExits calls onExit on observers whose filter matched before but no
+// longer matches after. A nil signature stands for an entity that does not
+// exist.
+func (w *World) fireExits(entity EntityID, before, after BitSet) {
+	for _, o := range w.observersSnapshot() {
+		if o.onExit != nil && o.matches(before) && !o.matches(after) {
+			o.onExit(w, entity)
+		}
+	}
+}
+
+// fireEnters calls onEnter on observers whose filter matches after but did
+// not match before. A nil signature stands for an entity that does not exist.
+func (w *World) fireEnters(entity EntityID, before, after BitSet) {
+	for _, o := range w.observersSnapshot() {
+		if o.onEnter != nil && !o.matches(before) && o.matches(after) {
+			o.onEnter(w, entity)
+		}
+	}
+}
+
+func (w *World) observersSnapshot() []*Observer {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.observers
+}
+
+func (o *Observer) matches(sig BitSet) bool {
+	return sig != nil && o.filter.matches(sig)
+}
@@ -0,0 +1,180 @@
+package ecs
+
+import "fmt"
+
+// Prefab is a template describing the components of an entity and,
+// optionally, of child entities created along with it.
+type Prefab struct {
+	Name       string
+	Components []Component
+	Children   []*Prefab
+}
+
+// NewPrefab creates a prefab from template components.
+func NewPrefab(name string, components ...Component) *Prefab {
+	return &Prefab{
+		Name:       name,
+		Components: components,
+	}
+}
+
+// WithChildren adds child prefabs, instantiated as ChildOf the entity.
+func (p *Prefab) WithChildren(children ...*Prefab) *Prefab {
+	p.Children = append(p.Children, children...)
+	return p
+}
+
+// RegisterPrefab stores a prefab in the world under its name, replacing any
+// prefab with the same name.
+func (w *World) RegisterPrefab(p *Prefab) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.prefabs[p.Name] = p
+}
+
+// Prefab returns the prefab registered under name, or nil.
+func (w *World) Prefab(name string) *Prefab {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.prefabs[name]
+}
+
+// spawned is an entity created by Instantiate whose hooks are still pending
+type spawned struct {
+	entity     EntityID
+	signature  BitSet
+	components []Component
+}
+
+// Instantiate creates an entity from a prefab, along with its children. Each
+// override replaces the prefab component with the same ID, or is added when
+// the prefab has none. Pointer components are copied so instances never
+// share state with the prefab. The whole tree is inserted straight into its
+// final archetypes before any OnAdd hook or observer runs.
+func (w *World) Instantiate(p *Prefab, overrides ...Component) EntityID {
+	p.checkIDs()
+	for _, override := range overrides {
+		checkedID(override)
+	}
+	var created []spawned
+
+	w.mu.Lock()
+	entity := w.instantiate(p, overrides, nil, &created)
+	w.mu.Unlock()
+
+	w.announce(created)
+	return entity
+}
+
+// InstantiateN creates n entities from a prefab in bulk, like CreateEntities.
+// fill, which may be nil, is called for each entity with a row holding copies
+// of the prefab components in prefab order and may modify or replace them
+// with components of the same ID. Children are instantiated for every entity.
+// InstantiateN panics, before creating anything, when the prefab itself holds
+// a relationship pair; use Instantiate for such prefabs.
+func (w *World) InstantiateN(p *Prefab, n int, fill func(i int, row []Component)) []EntityID {
+	ids := make([]ComponentID, len(p.Components))
+	for j, comp := range p.Components {
+		if pair, ok := comp.(Pair); ok {
+			panic(fmt.Sprintf("ecs: InstantiateN with prefab %q holding pair (%d, %d), use Instantiate", p.Name, pair.Relation, pair.Target))
+		}
+		ids[j] = checkedID(comp)
+	}
+	for _, child := range p.Children {
+		child.checkIDs()
+	}
+
+	entities := w.CreateEntities(n, func(i int, row []Component) {
+		for j, comp := range p.Components {
+			row[j] = cloneComponent(comp)
+		}
+		if fill != nil {
+			fill(i, row)
+		}
+	}, ids...)
+
+	if len(p.Children) > 0 {
+		var created []spawned
+		w.mu.Lock()
+		for _, entity := range entities {
+			for _, child := range p.Children {
+				w.instantiate(child, nil, &entity, &created)
+			}
+		}
+		w.mu.Unlock()
+		w.announce(created)
+	}
+	return entities
+}
+
+// announce fires OnAdd hooks and observers for freshly inserted entities
+func (w *World) announce(created []spawned) {
+	for _, s := range created {
+		w.runHooks(s.entity, s.components, onAdd)
+		w.fireEnters(s.entity, nil, s.signature)
+	}
+}
+
+// checkIDs panics when p or its children hold a component using an ID
+// reserved for pairs, so Instantiate fails before taking the world lock
+func (p *Prefab) checkIDs() {
+	for _, comp := range p.Components {
+		checkedID(comp)
+	}
+	for _, child := range p.Children {
+		child.checkIDs()
+	}
+}
+
+// instantiate inserts p and its children, relating them to parent when it is
+// not nil. The caller must hold w.mu and have checked the component IDs.
+func (w *World) instantiate(p *Prefab, overrides []Component, parent *EntityID, created *[]spawned) EntityID {
+	components := make([]Component, 0, len(p.Components)+len(overrides)+1)
+	for _, comp := range p.Components {
+		components = append(components, cloneComponent(comp))
+	}
+	for _, override := range overrides {
+		components = setComponent(components, override)
+	}
+	if parent != nil {
+		pair := Pair{
+			id:       w.pairID(ChildOf, *parent),
+			Relation: ChildOf,
+			Target:   *parent,
+		}
+		components = setComponent(components, pair)
+	}
+
+	signature := BitSet{}
+	componentMap := make(map[ComponentID]Component, len(components))
+	for _, comp := range components {
+		id := comp.ID()
+		signature.Set(id)
+		componentMap[id] = comp
+	}
+
+	entity := w.insert(signature, componentMap)
+	*created = append(*created, spawned{
+		entity:     entity,
+		signature:  signature,
+		components: components,
+	})
+
+	for _, child := range p.Children {
+		w.instantiate(child, nil, &entity, created)
+	}
+	return entity
+}
+
+// setComponent replaces the component of components with the ID of c, or
+// appends c
+func setComponent(components []Component, c Component) []Component {
+	id := c.ID()
+	for i, comp := range components {
+		if comp.ID() == id {
+			components[i] = c
+			return components
+		}
+	}
+	return append(components, c)
+}
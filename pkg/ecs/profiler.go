@@ -0,0 +1,191 @@
+package ecs
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// SystemStats holds the measurements of a single system for one frame.
+type SystemStats struct {
+	Name     string
+	Duration time.Duration
+	// Rows is the number of query rows yielded while the system ran, from any
+	// goroutine. An entity matched by several queries counts once per query.
+	Rows int
+}
+
+// FrameStats holds the measurements of one World.Update call.
+type FrameStats struct {
+	Frame    uint64
+	Duration time.Duration
+	Systems  []SystemStats
+}
+
+// SystemSummary aggregates the measurements of a system over the recorded frames.
+type SystemSummary struct {
+	Name  string
+	Calls int
+	Total time.Duration
+	Max   time.Duration
+	// Rows is the number of query rows yielded across all calls.
+	Rows int
+}
+
+// Avg returns the average time spent in the system per call.
+func (s SystemSummary) Avg() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Calls)
+}
+
+// Stats is a snapshot of the frames recorded by the profiler, oldest first.
+type Stats struct {
+	Frames  []FrameStats
+	Systems []SystemSummary
+}
+
+// String formats the per-system summary as a table.
+func (s Stats) String() string {
+	var sb strings.Builder
+
+	var total time.Duration
+	for _, frame := range s.Frames {
+		total += frame.Duration
+	}
+	avg := time.Duration(0)
+	if len(s.Frames) > 0 {
+		avg = total / time.Duration(len(s.Frames))
+	}
+	fmt.Fprintf(&sb, "frames: %d, avg frame: %v\n", len(s.Frames), avg)
+
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SYSTEM\tCALLS\tAVG\tMAX\tTOTAL\tROWS")
+	for _, sys := range s.Systems {
+		rows := 0
+		if sys.Calls > 0 {
+			rows = sys.Rows / sys.Calls
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%v\t%v\t%v\t%d\n",
+			sys.Name, sys.Calls, sys.Avg(), sys.Max, sys.Total, rows)
+	}
+	tw.Flush()
+
+	return sb.String()
+}
+
+// profiler records per-system timings into a fixed size ring buffer.
+type profiler struct {
+	mu     sync.Mutex
+	frames []FrameStats
+	next   int
+	count  int
+	frame  uint64
+
+	// rows counts the query rows yielded while the current system runs.
+	rows    atomic.Int64
+	current FrameStats
+	start   time.Time
+}
+
+func newProfiler(frames int) *profiler {
+	return &profiler{frames: make([]FrameStats, frames)}
+}
+
+func (p *profiler) beginFrame(systems int) {
+	p.current = FrameStats{
+		Frame:   p.frame,
+		Systems: make([]SystemStats, 0, systems),
+	}
+	p.frame++
+	p.start = time.Now()
+}
+
+func (p *profiler) beginSystem() time.Time {
+	p.rows.Store(0)
+	return time.Now()
+}
+
+func (p *profiler) endSystem(system System, start time.Time) {
+	p.current.Systems = append(p.current.Systems, SystemStats{
+		Name:     strings.TrimPrefix(fmt.Sprintf("%T", system), "*"),
+		Duration: time.Since(start),
+		Rows:     int(p.rows.Load()),
+	})
+}
+
+func (p *profiler) endFrame() {
+	p.current.Duration = time.Since(p.start)
+
+	p.mu.Lock()
+	p.frames[p.next] = p.current
+	p.next = (p.next + 1) % len(p.frames)
+	p.count = min(p.count+1, len(p.frames))
+	p.mu.Unlock()
+}
+
+func (p *profiler) stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := Stats{Frames: make([]FrameStats, 0, p.count)}
+	first := (p.next - p.count + len(p.frames)) % len(p.frames)
+	for i := range p.count {
+		stats.Frames = append(stats.Frames, p.frames[(first+i)%len(p.frames)])
+	}
+
+	index := make(map[string]int)
+	for _, frame := range stats.Frames {
+		for _, sys := range frame.Systems {
+			i, ok := index[sys.Name]
+			if !ok {
+				i = len(stats.Systems)
+				index[sys.Name] = i
+				stats.Systems = append(stats.Systems, SystemSummary{Name: sys.Name})
+			}
+			summary := &stats.Systems[i]
+			summary.Calls++
+			summary.Total += sys.Duration
+			summary.Max = max(summary.Max, sys.Duration)
+			summary.Rows += sys.Rows
+		}
+	}
+
+	return stats
+}
+
+// EnableProfiling starts recording per-system timings for the last frames
+// World.Update calls. Enabling it again discards the recorded frames.
+func (w *World) EnableProfiling(frames int) {
+	if frames <= 0 {
+		w.DisableProfiling()
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.profiler = newProfiler(frames)
+}
+
+// DisableProfiling stops recording timings and drops the recorded frames.
+func (w *World) DisableProfiling() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.profiler = nil
+}
+
+// Stats returns the frames recorded since profiling was enabled.
+func (w *World) Stats() Stats {
+	w.mu.RLock()
+	p := w.profiler
+	w.mu.RUnlock()
+
+	if p == nil {
+		return Stats{}
+	}
+	return p.stats()
+}
@@ -0,0 +1,206 @@
+package ecs
+
+import "fmt"
+
+// RelationID identifies a kind of relationship between two entities.
+type RelationID uint32
+
+// CascadePolicy decides what happens to the sources of a relationship when
+// its target is destroyed.
+type CascadePolicy uint8
+
+const (
+	// Orphan removes the relationship from its sources.
+	Orphan CascadePolicy = iota
+	// Delete destroys the sources along with their target.
+	Delete
+)
+
+// ChildOf relates an entity to its parent. Children are destroyed with their
+// parent.
+const ChildOf RelationID = 0
+
+var relationPolicies = map[RelationID]CascadePolicy{ChildOf: Delete}
+
+// RegisterRelation sets the cascade policy of a relation. Relations that are
+// never registered use Orphan.
+func RegisterRelation(id RelationID, policy CascadePolicy) {
+	relationPolicies[id] = policy
+}
+
+// PairIDBase is the first ComponentID handed out to relationship pairs.
+// Component types must use IDs below it: RegisterComponentType, CreateEntity
+// and AddComponents panic on other components with a higher ID. With the
+// 1 << iota IDs of gen_ids.go this leaves room for 12 component types.
+const PairIDBase ComponentID = 1 << 12
+
+// Pair is the component a source entity holds for each (Relation, Target)
+// relationship. Every pair gets its own ComponentID, so pairs take part in
+// archetype signatures and filters like any other component.
+//
+// That makes relationships cheap to query but not free to create: the sources
+// of each target share an archetype of their own, so a hierarchy with many
+// parents spreads its children over as many archetypes, and the signature of
+// any entity holding a pair spans more than PairIDBase/64 words.
+type Pair struct {
+	id       ComponentID
+	Relation RelationID
+	Target   EntityID
+}
+
+func (p Pair) ID() ComponentID {
+	return p.id
+}
+
+// checkedID returns the ID of c, panicking when a component other than a
+// Pair uses an ID reserved for pairs
+func checkedID(c Component) ComponentID {
+	id := c.ID()
+	if _, pair := c.(Pair); !pair && id >= PairIDBase {
+		panic(fmt.Sprintf("ecs: component %T has ID %d, component IDs must be below PairIDBase (%d)", c, id, PairIDBase))
+	}
+	return id
+}
+
+type pairKey struct {
+	relation RelationID
+	target   EntityID
+}
+
+// relationIndex maps relationship pairs to the component IDs given to them
+type relationIndex struct {
+	ids      map[pairKey]ComponentID
+	keys     map[ComponentID]pairKey
+	byTarget map[EntityID][]ComponentID
+	nextID   ComponentID
+	freeIDs  []ComponentID
+}
+
+func newRelationIndex() relationIndex {
+	return relationIndex{
+		ids:      make(map[pairKey]ComponentID),
+		keys:     make(map[ComponentID]pairKey),
+		byTarget: make(map[EntityID][]ComponentID),
+		nextID:   PairIDBase,
+	}
+}
+
+// PairID returns the ComponentID of the (relation, target) pair, allocating
+// it on first use. Use it to build filters such as all children of an entity:
+//
+//	ecs.NewFilter(w.PairID(ecs.ChildOf, parent))
+func (w *World) PairID(relation RelationID, target EntityID) ComponentID {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.pairID(relation, target)
+}
+
+// pairID is PairID for callers holding w.mu
+func (w *World) pairID(relation RelationID, target EntityID) ComponentID {
+	r := &w.relations
+	key := pairKey{relation: relation, target: target}
+	if id, ok := r.ids[key]; ok {
+		return id
+	}
+
+	var id ComponentID
+	if n := len(r.freeIDs); n > 0 {
+		id = r.freeIDs[n-1]
+		r.freeIDs = r.freeIDs[:n-1]
+	} else {
+		id = r.nextID
+		r.nextID++
+	}
+	r.ids[key] = id
+	r.keys[id] = key
+	r.byTarget[target] = append(r.byTarget[target], id)
+	return id
+}
+
+// AddPair relates source to target. It reports false if either entity does
+// not exist.
+func (w *World) AddPair(source EntityID, relation RelationID, target EntityID) bool {
+	if !w.Alive(target) {
+		return false
+	}
+	pair := Pair{
+		id:       w.PairID(relation, target),
+		Relation: relation,
+		Target:   target,
+	}
+	return w.AddComponents(source, pair)
+}
+
+// RemovePair removes the relationship between source and target.
+func (w *World) RemovePair(source EntityID, relation RelationID, target EntityID) bool {
+	w.mu.RLock()
+	id, ok := w.relations.ids[pairKey{relation: relation, target: target}]
+	w.mu.RUnlock()
+
+	if !ok {
+		return false
+	}
+	return w.RemoveComponents(source, id)
+}
+
+// Targets returns the entities source is related to through relation.
+func (w *World) Targets(source EntityID, relation RelationID) []EntityID {
+	components, _ := w.Components(source)
+
+	var targets []EntityID
+	for _, comp := range components {
+		if pair, ok := comp.(Pair); ok && pair.Relation == relation {
+			targets = append(targets, pair.Target)
+		}
+	}
+	return targets
+}
+
+// Sources returns the entities related to target through relation, such as
+// the children of an entity for ChildOf.
+func (w *World) Sources(relation RelationID, target EntityID) []EntityID {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	id, ok := w.relations.ids[pairKey{relation: relation, target: target}]
+	if !ok {
+		return nil
+	}
+
+	var sources []EntityID
+	for _, arch := range w.archetypesByComponent[id] {
+		arch.mu.RLock()
+		sources = append(sources, arch.entities...)
+		arch.mu.RUnlock()
+	}
+	return sources
+}
+
+// cascade applies the relation policies to the sources of a destroyed target
+// and releases the pair IDs pointing at it
+func (w *World) cascade(target EntityID) {
+	w.mu.Lock()
+	ids := w.relations.byTarget[target]
+	delete(w.relations.byTarget, target)
+	w.mu.Unlock()
+
+	for _, id := range ids {
+		w.mu.RLock()
+		key := w.relations.keys[id]
+		w.mu.RUnlock()
+
+		for _, source := range w.Sources(key.relation, target) {
+			if relationPolicies[key.relation] == Delete {
+				w.DestroyEntity(source)
+			} else {
+				w.RemoveComponents(source, id)
+			}
+		}
+
+		w.mu.Lock()
+		delete(w.relations.ids, key)
+		delete(w.relations.keys, id)
+		w.relations.freeIDs = append(w.relations.freeIDs, id)
+		w.mu.Unlock()
+	}
+}
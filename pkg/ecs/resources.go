@@ -0,0 +1,29 @@
+package ecs
+
+import "reflect"
+
+// SetResource stores r as the world-wide singleton of type T, replacing any
+// previous one. Resources hold state that belongs to no entity in particular.
+func SetResource[T any](w *World, r *T) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.resources[reflect.TypeFor[T]()] = r
+}
+
+// GetResource returns the resource of type T, or nil if none was set.
+func GetResource[T any](w *World) *T {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if r, ok := w.resources[reflect.TypeFor[T]()]; ok {
+		return r.(*T)
+	}
+	return nil
+}
+
+// RemoveResource removes the resource of type T from the world.
+func RemoveResource[T any](w *World) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.resources, reflect.TypeFor[T]())
+}
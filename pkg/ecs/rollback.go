@@ -0,0 +1,231 @@
+package ecs
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Determinism: given the same initial state, the same sequence of calls and
+// systems that only depend on their inputs, two worlds run identically.
+// Archetypes are kept in creation order, entities in insertion order with
+// swap removal, and systems, observers and hooks run in registration order;
+// no World operation depends on map iteration order or on time.
+
+// rollback keeps the captures of the last frames in a ring buffer. Slots are
+// reused so that capturing a frame does not allocate once the buffer is warm.
+type rollback struct {
+	captures []capture
+	next     int
+	count    int
+}
+
+// capture is the state of a World at the end of one update
+type capture struct {
+	frame        uint64
+	tick         uint64
+	lastRun      uint64
+	systemTicks  []uint64
+	nextEntityID EntityID
+	archetypes   []archetypeCapture
+	relations    relationIndex
+}
+
+// archetypeCapture holds the rows of one archetype, in the order of the
+// archetype's component slots
+type archetypeCapture struct {
+	entities []EntityID
+	columns  [][]Component
+	ticks    [][]componentTicks
+}
+
+// EnableRollback starts capturing the state of the world at the end of every
+// update, keeping the last frames captures, so that Rollback can rewind up to
+// frames updates. The current state is captured right away. A non-positive
+// frames disables rollback.
+//
+// Captures hold the entities, components, relationships and change ticks of
+// the world. Resources, events, observers and the state of systems are not
+// captured; keep what must be rewound, such as inputs, in components or
+// restore it alongside Rollback.
+func (w *World) EnableRollback(frames int) {
+	if frames <= 0 {
+		w.DisableRollback()
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rollback = &rollback{captures: make([]capture, frames)}
+	w.rollback.capture(w)
+}
+
+// DisableRollback stops capturing and drops the captures.
+func (w *World) DisableRollback() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rollback = nil
+}
+
+// Tick returns the number of updates the world has run, the tick Rollback
+// rewinds to.
+func (w *World) Tick() uint64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.frame
+}
+
+// Rollback restores the world to its state at the end of the update that
+// brought it to tick, dropping the captures of later ticks, so that running
+// the same updates again gives the same results. It reports false when no
+// capture of tick is kept. No hooks or observers fire.
+func (w *World) Rollback(tick uint64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r := w.rollback
+	if r == nil {
+		return false
+	}
+	for i := range r.count {
+		index := (r.next - 1 - i + 2*len(r.captures)) % len(r.captures)
+		if r.captures[index].frame != tick {
+			continue
+		}
+		w.restore(&r.captures[index])
+		r.next = (index + 1) % len(r.captures)
+		r.count -= i
+		return true
+	}
+	return false
+}
+
+// capture records the state of w into the next slot. The caller must hold w.mu.
+func (r *rollback) capture(w *World) {
+	c := &r.captures[r.next]
+	r.next = (r.next + 1) % len(r.captures)
+	r.count = min(r.count+1, len(r.captures))
+
+	c.frame = w.frame
+	c.tick = w.tick
+	c.lastRun = w.lastRun
+	c.systemTicks = append(c.systemTicks[:0], w.systemTicks...)
+	c.nextEntityID = w.nextEntityID
+	c.relations = copyRelations(w.relations, c.relations)
+
+	if cap(c.archetypes) < len(w.archetypes) {
+		c.archetypes = slices.Grow(c.archetypes[:0], len(w.archetypes))
+	}
+	c.archetypes = c.archetypes[:len(w.archetypes)]
+	for i, arch := range w.archetypes {
+		ac := &c.archetypes[i]
+		arch.mu.RLock()
+		ac.entities = append(ac.entities[:0], arch.entities...)
+		if len(ac.columns) != len(arch.components) {
+			ac.columns = make([][]Component, len(arch.components))
+			ac.ticks = make([][]componentTicks, len(arch.components))
+		}
+		for j, slot := range arch.components {
+			ac.columns[j] = copyColumn(ac.columns[j], slot.data)
+			ac.ticks[j] = append(ac.ticks[j][:0], slot.ticks...)
+		}
+		arch.mu.RUnlock()
+	}
+}
+
+// restore puts the state of c back into w. The caller must hold w.mu.
+func (w *World) restore(c *capture) {
+	w.frame = c.frame
+	w.tick = c.tick
+	w.lastRun = c.lastRun
+	if len(c.systemTicks) == len(w.systemTicks) {
+		copy(w.systemTicks, c.systemTicks)
+	}
+	w.nextEntityID = c.nextEntityID
+	w.relations = copyRelations(c.relations, relationIndex{})
+
+	clear(w.entityData)
+	for i, arch := range w.archetypes {
+		arch.mu.Lock()
+		clear(arch.entityIndex)
+		if i >= len(c.archetypes) {
+			arch.entities = arch.entities[:0]
+			for j := range arch.components {
+				slot := &arch.components[j]
+				clear(slot.data)
+				slot.data = slot.data[:0]
+				slot.ticks = slot.ticks[:0]
+			}
+			arch.mu.Unlock()
+			continue
+		}
+
+		ac := &c.archetypes[i]
+		arch.entities = append(arch.entities[:0], ac.entities...)
+		for index, entity := range arch.entities {
+			arch.entityIndex[entity] = index
+			w.entityData[entity] = EntityData{archetype: arch, index: index}
+		}
+		for j := range arch.components {
+			slot := &arch.components[j]
+			clear(slot.data)
+			slot.data = slot.data[:0]
+			for _, comp := range ac.columns[j] {
+				slot.data = append(slot.data, cloneComponent(comp))
+			}
+			slot.ticks = append(slot.ticks[:0], ac.ticks[j]...)
+		}
+		arch.mu.Unlock()
+	}
+	w.invalidateQueries()
+}
+
+// copyColumn copies components into dst, reusing the values dst points to
+// when they have the same type so that steady state captures do not allocate
+func copyColumn(dst, src []Component) []Component {
+	reused := dst[:min(len(dst), len(src))]
+	dst = dst[:0]
+	for i, comp := range src {
+		if i < len(reused) {
+			if into, ok := copyInto(reused[i], comp); ok {
+				dst = append(dst, into)
+				continue
+			}
+		}
+		dst = append(dst, cloneComponent(comp))
+	}
+	clear(dst[len(dst):cap(dst)])
+	return dst
+}
+
+// copyInto copies the value src points to into the value dst points to
+func copyInto(dst, src Component) (Component, bool) {
+	if _, ok := src.(Cloner); ok {
+		return nil, false
+	}
+	s := reflect.ValueOf(src)
+	d := reflect.ValueOf(dst)
+	if s.Kind() != reflect.Pointer || s.IsNil() || d.Type() != s.Type() || d.IsNil() {
+		return nil, false
+	}
+	d.Elem().Set(s.Elem())
+	return dst, true
+}
+
+// copyRelations deep copies a relation index, reusing the maps of dst
+func copyRelations(src, dst relationIndex) relationIndex {
+	if dst.ids == nil {
+		dst = newRelationIndex()
+	}
+	clear(dst.ids)
+	maps.Copy(dst.ids, src.ids)
+	clear(dst.keys)
+	maps.Copy(dst.keys, src.keys)
+	clear(dst.byTarget)
+	for target, ids := range src.byTarget {
+		dst.byTarget[target] = slices.Clone(ids)
+	}
+	dst.nextID = src.nextID
+	dst.freeIDs = append(dst.freeIDs[:0], src.freeIDs...)
+	return dst
+}
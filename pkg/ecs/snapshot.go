@@ -0,0 +1,289 @@
+package ecs
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// worldJSON is the JSON form of a World
+type worldJSON struct {
+	NextEntity EntityID          `json:"nextEntity"`
+	Versions   map[string]uint32 `json:"versions,omitempty"`
+	Entities   []entityJSON      `json:"entities"`
+}
+
+// entityJSON holds the components of an entity keyed by registered type name
+type entityJSON struct {
+	ID         EntityID                   `json:"id"`
+	Components map[string]json.RawMessage `json:"components"`
+	Pairs      []pairJSON                 `json:"pairs,omitempty"`
+}
+
+type pairJSON struct {
+	Relation RelationID `json:"relation"`
+	Target   EntityID   `json:"target"`
+}
+
+// MarshalJSON encodes every entity of the world, ordered by ID, with its
+// components keyed by the type name given to RegisterComponentType and its
+// relationship pairs. Component types with a schema version other than 0 have
+// it recorded so LoadWorld can migrate them. Systems, resources, events and
+// change ticks are not part of the snapshot. Every component must be
+// registered and stored as the registered type.
+func (w *World) MarshalJSON() ([]byte, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	snapshot := worldJSON{
+		NextEntity: w.nextEntityID,
+		Versions:   make(map[string]uint32),
+		Entities:   make([]entityJSON, 0, len(w.entityData)),
+	}
+	for _, arch := range w.archetypes {
+		arch.mu.RLock()
+		for index, entity := range arch.entities {
+			e, err := encodeEntity(entity, arch.rowLocked(index), snapshot.Versions)
+			if err != nil {
+				arch.mu.RUnlock()
+				return nil, err
+			}
+			snapshot.Entities = append(snapshot.Entities, e)
+		}
+		arch.mu.RUnlock()
+	}
+	slices.SortFunc(snapshot.Entities, func(a, b entityJSON) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return json.Marshal(snapshot)
+}
+
+// encodeEntity encodes the components of an entity and records their schema
+// versions in versions
+func encodeEntity(entity EntityID, row []Component, versions map[string]uint32) (entityJSON, error) {
+	e := entityJSON{
+		ID:         entity,
+		Components: make(map[string]json.RawMessage, len(row)),
+	}
+	for _, comp := range row {
+		if pair, ok := comp.(Pair); ok {
+			e.Pairs = append(e.Pairs, pairJSON{Relation: pair.Relation, Target: pair.Target})
+			continue
+		}
+
+		info, ok := componentTypes[comp.ID()]
+		if !ok || info.typ == nil {
+			return e, fmt.Errorf("ecs: component %d of entity %d is not registered", comp.ID(), entity)
+		}
+		if t := reflect.TypeOf(comp); t != info.typ {
+			return e, fmt.Errorf("ecs: component %s of entity %d is stored as %s", info.typ, entity, t)
+		}
+		data, err := json.Marshal(comp)
+		if err != nil {
+			return e, fmt.Errorf("ecs: encoding %s of entity %d: %w", info.typeName, entity, err)
+		}
+		e.Components[info.typeName] = data
+		if info.version != 0 {
+			versions[info.typeName] = info.version
+		}
+	}
+	return e, nil
+}
+
+// LoadWorld creates a World from data produced by World.MarshalJSON. Entities
+// keep their IDs and are grouped into archetypes as when they were saved, and
+// components saved with an older schema version are migrated. No hooks run;
+// components count as added on the first update.
+func LoadWorld(data []byte) (*World, error) {
+	var snapshot worldJSON
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+
+	w := NewWorld()
+	w.nextEntityID = snapshot.NextEntity
+	for _, e := range snapshot.Entities {
+		if _, ok := w.entityData[e.ID]; ok {
+			return nil, fmt.Errorf("ecs: duplicate entity %d", e.ID)
+		}
+
+		components, err := decodeEntity(e, snapshot.Versions)
+		if err != nil {
+			return nil, err
+		}
+		signature := BitSet{}
+		componentMap := make(map[ComponentID]Component, len(components))
+		for _, comp := range components {
+			if pair, ok := comp.(Pair); ok {
+				pair.id = w.pairID(pair.Relation, pair.Target)
+				comp = pair
+			}
+			id := comp.ID()
+			signature.Set(id)
+			componentMap[id] = comp
+		}
+
+		w.insertAt(e.ID, signature, componentMap)
+		w.nextEntityID = max(w.nextEntityID, e.ID+1)
+	}
+	return w, nil
+}
+
+// decodeEntity decodes the components of an entity ordered by ID, followed
+// by its pairs, which have no ID yet
+func decodeEntity(e entityJSON, versions map[string]uint32) ([]Component, error) {
+	components := make([]Component, 0, len(e.Components)+len(e.Pairs))
+	for name, raw := range e.Components {
+		comp, err := decodeComponent(name, raw, versions[name])
+		if err != nil {
+			return nil, fmt.Errorf("ecs: entity %d: %w", e.ID, err)
+		}
+		components = append(components, comp)
+	}
+	slices.SortFunc(components, func(a, b Component) int {
+		return cmp.Compare(a.ID(), b.ID())
+	})
+	for _, p := range e.Pairs {
+		components = append(components, Pair{Relation: p.Relation, Target: p.Target})
+	}
+	return components, nil
+}
+
+// decodeComponent decodes raw, saved with the given schema version, into the
+// type registered under name
+func decodeComponent(name string, raw json.RawMessage, version uint32) (Component, error) {
+	id, ok := componentNames[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown component type %q", name)
+	}
+	info := componentTypes[id]
+	t, err := info.decodeType(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if version != info.version {
+		v := reflect.New(t)
+		if err := json.Unmarshal(raw, v.Interface()); err != nil {
+			return nil, fmt.Errorf("decoding %s version %d: %w", name, version, err)
+		}
+		return info.upgrade(v.Elem().Interface(), version)
+	}
+	if t.Kind() == reflect.Pointer {
+		v := reflect.New(t.Elem())
+		if err := json.Unmarshal(raw, v.Interface()); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", name, err)
+		}
+		return v.Interface().(Component), nil
+	}
+	v := reflect.New(t)
+	if err := json.Unmarshal(raw, v.Interface()); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", name, err)
+	}
+	return v.Elem().Interface().(Component), nil
+}
+
+// deltaJSON is the JSON form of a Delta
+type deltaJSON struct {
+	NextEntity EntityID          `json:"nextEntity"`
+	Versions   map[string]uint32 `json:"versions,omitempty"`
+	Created    []entityJSON      `json:"created,omitempty"`
+	Destroyed  []EntityID        `json:"destroyed,omitempty"`
+	Updated    []updateJSON      `json:"updated,omitempty"`
+}
+
+// updateJSON holds the set components of an entity like entityJSON, and the
+// removed ones by type name
+type updateJSON struct {
+	entityJSON
+	Removed      []string   `json:"removed,omitempty"`
+	RemovedPairs []pairJSON `json:"removedPairs,omitempty"`
+}
+
+// MarshalJSON encodes the delta with components keyed by registered type
+// name, like World.MarshalJSON.
+func (d *Delta) MarshalJSON() ([]byte, error) {
+	out := deltaJSON{
+		NextEntity: d.NextEntity,
+		Versions:   make(map[string]uint32),
+		Destroyed:  d.Destroyed,
+	}
+	for _, state := range d.Created {
+		e, err := encodeEntity(state.Entity, state.Components, out.Versions)
+		if err != nil {
+			return nil, err
+		}
+		out.Created = append(out.Created, e)
+	}
+
+	for _, update := range d.Updated {
+		e, err := encodeEntity(update.Entity, update.Set, out.Versions)
+		if err != nil {
+			return nil, err
+		}
+		u := updateJSON{entityJSON: e}
+		for _, comp := range update.Removed {
+			if pair, ok := comp.(Pair); ok {
+				u.RemovedPairs = append(u.RemovedPairs, pairJSON{Relation: pair.Relation, Target: pair.Target})
+				continue
+			}
+			info, ok := componentTypes[comp.ID()]
+			if !ok || info.typ == nil {
+				return nil, fmt.Errorf("ecs: component %d of entity %d is not registered", comp.ID(), update.Entity)
+			}
+			u.Removed = append(u.Removed, info.typeName)
+		}
+		out.Updated = append(out.Updated, u)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes a delta encoded by MarshalJSON, migrating components
+// saved with an older schema version. Removed components are zero values of
+// their registered type.
+func (d *Delta) UnmarshalJSON(data []byte) error {
+	var in deltaJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	*d = Delta{NextEntity: in.NextEntity, Destroyed: in.Destroyed}
+	for _, e := range in.Created {
+		components, err := decodeEntity(e, in.Versions)
+		if err != nil {
+			return err
+		}
+		d.Created = append(d.Created, EntityState{Entity: e.ID, Components: components})
+	}
+
+	for _, u := range in.Updated {
+		set, err := decodeEntity(u.entityJSON, in.Versions)
+		if err != nil {
+			return err
+		}
+		update := EntityUpdate{Entity: u.ID, Set: set}
+		for _, name := range u.Removed {
+			id, ok := componentNames[name]
+			if !ok {
+				return fmt.Errorf("ecs: entity %d: unknown component type %q", u.ID, name)
+			}
+			update.Removed = append(update.Removed, zeroComponent(componentTypes[id].typ))
+		}
+		for _, p := range u.RemovedPairs {
+			update.Removed = append(update.Removed, Pair{Relation: p.Relation, Target: p.Target})
+		}
+		d.Updated = append(d.Updated, update)
+	}
+	return nil
+}
+
+// zeroComponent returns the zero value of a component type, pointing to one
+// for pointer types
+func zeroComponent(t reflect.Type) Component {
+	if t.Kind() == reflect.Pointer {
+		return reflect.New(t.Elem()).Interface().(Component)
+	}
+	return reflect.Zero(t).Interface().(Component)
+}
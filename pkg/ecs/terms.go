@@ -0,0 +1,113 @@
+package ecs
+
+import "encoding/binary"
+
+// Term is a boolean condition on the components of an archetype, combined
+// with And, Or and Not and attached to a filter with Filter.Where.
+type Term interface {
+	match(sig BitSet) bool
+	// appendKey appends an encoding of the term to a query cache key that
+	// differs between terms that are written differently
+	appendKey(key []byte) []byte
+}
+
+type allOf BitSet
+
+func (t allOf) match(sig BitSet) bool { return sig.ContainsAll(BitSet(t)) }
+
+func (t allOf) appendKey(key []byte) []byte {
+	return appendBits(append(key, 'H'), BitSet(t))
+}
+
+type anyOf BitSet
+
+func (t anyOf) match(sig BitSet) bool { return BitSet(t).Intersects(sig) }
+
+func (t anyOf) appendKey(key []byte) []byte {
+	return appendBits(append(key, 'A'), BitSet(t))
+}
+
+type andTerm []Term
+
+func (t andTerm) match(sig BitSet) bool {
+	for _, term := range t {
+		if !term.match(sig) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t andTerm) appendKey(key []byte) []byte { return appendTerms(append(key, '&'), t) }
+
+type orTerm []Term
+
+func (t orTerm) match(sig BitSet) bool {
+	for _, term := range t {
+		if term.match(sig) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t orTerm) appendKey(key []byte) []byte { return appendTerms(append(key, '|'), t) }
+
+type notTerm struct{ term Term }
+
+func (t notTerm) match(sig BitSet) bool { return !t.term.match(sig) }
+
+func (t notTerm) appendKey(key []byte) []byte {
+	return t.term.appendKey(append(key, '!'))
+}
+
+// appendTerms appends the number of terms then each term, so nested terms
+// cannot be mistaken for siblings
+func appendTerms(key []byte, terms []Term) []byte {
+	key = binary.AppendUvarint(key, uint64(len(terms)))
+	for _, term := range terms {
+		key = term.appendKey(key)
+	}
+	return key
+}
+
+func bitsOf(ids []ComponentID) BitSet {
+	set := BitSet{}
+	for _, id := range ids {
+		set.Set(id)
+	}
+	return set
+}
+
+// Has matches archetypes containing all of the components.
+func Has(ids ...ComponentID) Term {
+	return allOf(bitsOf(ids))
+}
+
+// AnyOf matches archetypes containing at least one of the components.
+func AnyOf(ids ...ComponentID) Term {
+	return anyOf(bitsOf(ids))
+}
+
+// And matches archetypes satisfying every term.
+func And(terms ...Term) Term {
+	return andTerm(terms)
+}
+
+// Or matches archetypes satisfying at least one term.
+func Or(terms ...Term) Term {
+	return orTerm(terms)
+}
+
+// Not matches archetypes that do not satisfy term.
+func Not(term Term) Term {
+	return notTerm{term: term}
+}
+
+// Where restricts the filter to archetypes satisfying every term, on top of
+// its included and excluded components. Terms only select archetypes; use
+// Optional to get columns for the components they mention.
+func (f *Filter) Where(terms ...Term) *Filter {
+	f.terms = append(f.terms, terms...)
+	return f
+}
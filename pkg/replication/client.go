@@ -0,0 +1,188 @@
+package replication
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// Client applies the deltas streamed by a Server to a local World. Entities
+// get local IDs, so the World may hold entities of its own; components
+// implementing ecs.EntityMapper have their server IDs translated.
+// Relationship pairs are not replicated; any pair received is dropped.
+//
+// Either call Receive in a loop, or add the client to the World's systems:
+// it then reads deltas in the background and applies them on Update.
+type Client struct {
+	world *ecs.World
+	conn  net.Conn
+	dec   *json.Decoder
+
+	// ids guards local and remote, which apply alone writes while Local and
+	// Remote may read them from any goroutine
+	ids    sync.RWMutex
+	local  map[ecs.EntityID]ecs.EntityID
+	remote map[ecs.EntityID]ecs.EntityID
+
+	mu      sync.Mutex
+	pending []*ecs.Delta
+	err     error
+}
+
+// NewClient creates a client applying what it receives on conn to world.
+func NewClient(world *ecs.World, conn net.Conn) *Client {
+	return &Client{
+		world:  world,
+		conn:   conn,
+		dec:    json.NewDecoder(conn),
+		local:  make(map[ecs.EntityID]ecs.EntityID),
+		remote: make(map[ecs.EntityID]ecs.EntityID),
+	}
+}
+
+// Receive blocks until a delta arrives and applies it. It returns io.EOF once
+// the server closed the connection.
+func (c *Client) Receive() error {
+	delta, err := c.read()
+	if err != nil {
+		return err
+	}
+	c.apply(delta)
+	return nil
+}
+
+// Local returns the local ID of a server entity.
+func (c *Client) Local(server ecs.EntityID) (ecs.EntityID, bool) {
+	c.ids.RLock()
+	defer c.ids.RUnlock()
+	id, ok := c.local[server]
+	return id, ok
+}
+
+// Remote returns the server ID of a replicated local entity.
+func (c *Client) Remote(local ecs.EntityID) (ecs.EntityID, bool) {
+	c.ids.RLock()
+	defer c.ids.RUnlock()
+	id, ok := c.remote[local]
+	return id, ok
+}
+
+// Init starts reading deltas in the background.
+func (c *Client) Init() {
+	go func() {
+		for {
+			delta, err := c.read()
+			c.mu.Lock()
+			if err != nil {
+				c.err = err
+				c.mu.Unlock()
+				return
+			}
+			c.pending = append(c.pending, delta)
+			c.mu.Unlock()
+		}
+	}()
+}
+
+// Update applies the deltas received since the last update.
+func (c *Client) Update(_ float64) {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	for _, delta := range pending {
+		c.apply(delta)
+	}
+}
+
+// Err returns the error that stopped the background reader, io.EOF once the
+// server closed the connection.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Shutdown closes the connection.
+func (c *Client) Shutdown() {
+	c.conn.Close()
+}
+
+func (c *Client) read() (*ecs.Delta, error) {
+	var delta ecs.Delta
+	if err := c.dec.Decode(&delta); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	return &delta, nil
+}
+
+// apply applies a delta translating server entity IDs to local ones
+func (c *Client) apply(d *ecs.Delta) {
+	for _, entity := range d.Destroyed {
+		if id, ok := c.local[entity]; ok {
+			c.world.DestroyEntity(id)
+			c.ids.Lock()
+			delete(c.local, entity)
+			delete(c.remote, id)
+			c.ids.Unlock()
+		}
+	}
+
+	// Allocate every created entity first so components can refer to each other
+	for _, state := range d.Created {
+		if _, ok := c.local[state.Entity]; ok {
+			continue
+		}
+		id := c.world.CreateEntity()
+		c.ids.Lock()
+		c.local[state.Entity] = id
+		c.remote[id] = state.Entity
+		c.ids.Unlock()
+	}
+	for _, state := range d.Created {
+		c.world.AddComponents(c.local[state.Entity], c.mapEntities(state.Components)...)
+	}
+
+	for _, update := range d.Updated {
+		id, ok := c.local[update.Entity]
+		if !ok {
+			continue
+		}
+		if len(update.Removed) > 0 {
+			ids := make([]ecs.ComponentID, 0, len(update.Removed))
+			for _, comp := range update.Removed {
+				if _, pair := comp.(ecs.Pair); !pair {
+					ids = append(ids, comp.ID())
+				}
+			}
+			c.world.RemoveComponents(id, ids...)
+		}
+		if len(update.Set) > 0 {
+			c.world.AddComponents(id, c.mapEntities(update.Set)...)
+		}
+	}
+}
+
+// mapEntities translates the server IDs held by EntityMapper components and
+// drops relationship pairs, which decode without an ID of this world
+func (c *Client) mapEntities(components []ecs.Component) []ecs.Component {
+	mapped := components[:0]
+	for _, comp := range components {
+		switch comp := comp.(type) {
+		case ecs.Pair:
+			continue
+		case ecs.EntityMapper:
+			mapped = append(mapped, comp.MapEntities(c.local))
+		default:
+			mapped = append(mapped, comp)
+		}
+	}
+	return mapped
+}
@@ -0,0 +1,54 @@
+// Package replication mirrors the replicated components of a server World on
+// client Worlds. The server streams deltas, encoded as JSON by ecs.Delta, over
+// any net.Conn; clients apply them under their own entity IDs.
+package replication
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+var (
+	mu         sync.RWMutex
+	replicated []ecs.ComponentID
+)
+
+// Replicate marks component types as replicated. Only replicated components
+// are sent to clients, and only entities holding at least one of them exist
+// on clients. Like ecs.RegisterComponentType it is meant to be called during
+// initialization; the components must be registered for the deltas to encode.
+// Relationship pairs are not replicated: their IDs differ from world to world,
+// and Replicate panics on IDs from ecs.PairIDBase up.
+func Replicate(ids ...ecs.ComponentID) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, id := range ids {
+		if id >= ecs.PairIDBase {
+			panic(fmt.Sprintf("replication: Replicate with ID %d, relationship pairs are not replicated", id))
+		}
+		if !slices.Contains(replicated, id) {
+			replicated = append(replicated, id)
+		}
+	}
+}
+
+// Replicated reports whether a component type is replicated.
+func Replicated(id ecs.ComponentID) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return slices.Contains(replicated, id)
+}
+
+// snapshot copies the replicated components of w
+func snapshot(w *ecs.World) *ecs.Snapshot {
+	mu.RLock()
+	ids := slices.Clone(replicated)
+	mu.RUnlock()
+	if len(ids) == 0 {
+		return &ecs.Snapshot{}
+	}
+	return w.Snapshot(ids...)
+}
@@ -0,0 +1,179 @@
+package replication
+
+import (
+	"encoding/json"
+	"net"
+	"slices"
+	"sync"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// queueSize is how many deltas a client may lag behind before it is dropped
+const queueSize = 64
+
+// Server streams the replicated state of a World to its clients. Add it as
+// the last system of the World so every update sends the changes of the
+// frame.
+type Server struct {
+	world *ecs.World
+
+	mu      sync.Mutex
+	prev    *ecs.Snapshot
+	clients []*peer
+	err     error
+}
+
+// peer is a connected client with its queue of encoded deltas
+type peer struct {
+	conn  net.Conn
+	queue chan []byte
+	fresh bool
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewServer creates a server replicating world.
+func NewServer(world *ecs.World) *Server {
+	return &Server{
+		world: world,
+		prev:  &ecs.Snapshot{},
+	}
+}
+
+// Accept starts replicating to the client at the other end of conn. It
+// receives the full replicated state on the next update, then deltas.
+func (s *Server) Accept(conn net.Conn) {
+	p := &peer{
+		conn:  conn,
+		queue: make(chan []byte, queueSize),
+		fresh: true,
+		done:  make(chan struct{}),
+	}
+	go p.write()
+
+	s.mu.Lock()
+	s.clients = append(s.clients, p)
+	s.mu.Unlock()
+}
+
+// Clients returns the number of connected clients.
+func (s *Server) Clients() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, p := range s.clients {
+		if !p.closed() {
+			n++
+		}
+	}
+	return n
+}
+
+// Err returns the first error encoding a delta, which stops replication.
+// Replicated components must be registered with ecs.RegisterComponentType and
+// stored as the registered type.
+func (s *Server) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Update sends the changes since the previous update to every client, if
+// there are any. Clients that fail or lag more than queueSize deltas behind
+// are disconnected. Without clients nothing is computed; clients accepted
+// later start from the full state anyway.
+func (s *Server) Update(_ float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return
+	}
+	s.clients = slices.DeleteFunc(s.clients, (*peer).closed)
+	if len(s.clients) == 0 {
+		return
+	}
+
+	current := snapshot(s.world)
+	changes := ecs.DiffSnapshots(s.prev, current)
+	delta, err := json.Marshal(changes)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.prev = current
+
+	var full []byte
+	clients := s.clients[:0]
+	for _, p := range s.clients {
+		if p.closed() {
+			continue
+		}
+
+		data := delta
+		if p.fresh {
+			if full == nil {
+				if full, err = json.Marshal(ecs.DiffSnapshots(nil, current)); err != nil {
+					s.err = err
+					return
+				}
+			}
+			data = full
+		} else if changes.Empty() {
+			clients = append(clients, p)
+			continue
+		}
+
+		select {
+		case p.queue <- data:
+			p.fresh = false
+			clients = append(clients, p)
+		default:
+			p.close()
+		}
+	}
+	s.clients = clients
+}
+
+// Shutdown disconnects every client.
+func (s *Server) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range s.clients {
+		p.close()
+	}
+	s.clients = nil
+}
+
+// write sends queued deltas until the connection fails or is closed
+func (p *peer) write() {
+	for {
+		select {
+		case data := <-p.queue:
+			if _, err := p.conn.Write(data); err != nil {
+				p.close()
+				return
+			}
+		case <-p.done:
+			return
+		}
+	}
+}
+
+func (p *peer) close() {
+	p.once.Do(func() {
+		close(p.done)
+		p.conn.Close()
+	})
+}
+
+func (p *peer) closed() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,37 @@
+package ecstest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// Pair IDs start at PairIDBase, a multiple of 64, so the first pair's
+// signature hashes like the one of component 0. Alternating between them
+// must keep both archetypes apart.
+func TestArchetypesWithCollidingHashes(t *testing.T) {
+	w := ecs.NewWorld()
+	parent := w.CreateEntity(&Health{})
+	var children, tags []ecs.EntityID
+	for range 5 {
+		child := w.CreateEntity()
+		w.AddPair(child, ecs.ChildOf, parent)
+		children = append(children, child)
+		tags = append(tags, w.CreateEntity(&Tag0{}))
+	}
+
+	sources := w.Sources(ecs.ChildOf, parent)
+	slices.Sort(sources)
+	if !slices.Equal(sources, children) {
+		t.Errorf("children = %v, want %v", sources, children)
+	}
+	for _, tag := range tags {
+		if components, _ := w.Components(tag); len(components) != 1 {
+			t.Errorf("Tag0 entity %d has %d components, want 1", tag, len(components))
+		}
+	}
+	if got := len(ecs.NewFilter(0).Query(w)); got != 5 {
+		t.Errorf("%d Tag0 rows, want 5", got)
+	}
+}
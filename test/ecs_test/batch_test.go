@@ -0,0 +1,47 @@
+package ecstest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+func TestCreateEntitiesChecksRows(t *testing.T) {
+	w := ecs.NewWorld()
+	tests := []struct {
+		name string
+		ids  []ecs.ComponentID
+		fill func(i int, row []ecs.Component)
+		want string
+	}{
+		{"wrong ID", []ecs.ComponentID{1, 2}, func(_ int, row []ecs.Component) {
+			row[0], row[1] = &Velocity{}, &Position{}
+		}, "want component 1"},
+		{"nil slot", []ecs.ComponentID{1, 2}, func(i int, row []ecs.Component) {
+			row[0] = &Position{}
+			if i == 0 {
+				row[1] = &Velocity{}
+			}
+		}, "nil"},
+		{"duplicate ID", []ecs.ComponentID{1, 1}, func(_ int, row []ecs.Component) {
+			row[0], row[1] = &Position{}, &Position{}
+		}, "given twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				msg, _ := recover().(string)
+				if !strings.Contains(msg, tt.want) {
+					t.Errorf("panic %q, want one mentioning %q", msg, tt.want)
+				}
+			}()
+			w.CreateEntities(3, tt.fill, tt.ids...)
+		})
+	}
+
+	if rows := ecs.NewFilter(1).Query(w); len(rows) != 0 {
+		t.Errorf("%d entities created by failed batches", len(rows))
+	}
+}
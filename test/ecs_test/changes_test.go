@@ -0,0 +1,181 @@
+package ecstest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// stage is a system running a different function each frame
+type stage struct {
+	frame int
+	run   func(frame int)
+}
+
+func (s *stage) Update(float64) {
+	s.run(s.frame)
+	s.frame++
+}
+
+// seen returns the entities matching filter, sorted
+func seen(w *ecs.World, filter *ecs.Filter) []ecs.EntityID {
+	var entities []ecs.EntityID
+	it := filter.Iterator(w)
+	for it.Next() {
+		entities = append(entities, it.Entity())
+	}
+	slices.Sort(entities)
+	return entities
+}
+
+func changedPosition() *ecs.Filter {
+	filter := ecs.NewFilter(2)
+	return filter.Changed(1)
+}
+
+func TestChangedAcrossSystems(t *testing.T) {
+	w := ecs.NewWorld()
+	a := w.CreateEntity(&Position{}, &Velocity{})
+	b := w.CreateEntity(&Position{}, &Velocity{})
+	both := []ecs.EntityID{a, b}
+
+	// The writer marks a in frame 1 and b through its iterator in frame 3
+	var writes, reads [][]ecs.EntityID
+	writer := &stage{run: func(frame int) {
+		writes = append(writes, seen(w, changedPosition()))
+		filter := ecs.NewFilter(1)
+		it := filter.Iterator(w)
+		for it.Next() {
+			if frame == 1 && it.Entity() == a || frame == 3 && it.Entity() == b {
+				it.MarkChanged(0)
+			}
+		}
+	}}
+	reader := &stage{run: func(int) {
+		reads = append(reads, seen(w, changedPosition()))
+	}}
+	w.AddSystems(writer, reader)
+
+	for range 5 {
+		w.Update(0)
+	}
+
+	// A system never sees its own changes; systems after it see them in the
+	// same frame and systems before it in the next one
+	wantWrites := [][]ecs.EntityID{both, nil, nil, nil, nil}
+	wantReads := [][]ecs.EntityID{both, {a}, nil, {b}, nil}
+	for frame := range 5 {
+		if !slices.Equal(writes[frame], wantWrites[frame]) {
+			t.Errorf("frame %d: writer saw %v, want %v", frame, writes[frame], wantWrites[frame])
+		}
+		if !slices.Equal(reads[frame], wantReads[frame]) {
+			t.Errorf("frame %d: reader saw %v, want %v", frame, reads[frame], wantReads[frame])
+		}
+	}
+
+	// Changes between updates are seen by every system at the next update
+	writes, reads = nil, nil
+	w.MarkChanged(b, 1)
+	w.Update(0)
+	w.Update(0)
+	if !slices.Equal(writes[0], []ecs.EntityID{b}) || !slices.Equal(reads[0], []ecs.EntityID{b}) {
+		t.Errorf("after MarkChanged: writer saw %v, reader %v, want [%d]", writes[0], reads[0], b)
+	}
+	if writes[1] != nil || reads[1] != nil {
+		t.Errorf("next update: writer saw %v, reader %v, want none", writes[1], reads[1])
+	}
+
+	// Outside Update the previous update's changes are already seen
+	if got := seen(w, changedPosition()); got != nil {
+		t.Errorf("outside Update saw %v, want none", got)
+	}
+	w.MarkChanged(a, 1)
+	if got := seen(w, changedPosition()); !slices.Equal(got, []ecs.EntityID{a}) {
+		t.Errorf("outside Update after MarkChanged saw %v, want [%d]", got, a)
+	}
+}
+
+func TestAddedAcrossSystems(t *testing.T) {
+	w := ecs.NewWorld()
+	a := w.CreateEntity(&Position{})
+	b := w.CreateEntity(&Position{})
+
+	added := func() *ecs.Filter {
+		filter := ecs.NewFilter(1)
+		return filter.Added(3)
+	}
+
+	// The writer gives a Health in frame 0 and only marks it changed in
+	// frame 2, which Added filters ignore
+	var writes, reads [][]ecs.EntityID
+	writer := &stage{run: func(frame int) {
+		writes = append(writes, seen(w, added()))
+		switch frame {
+		case 0:
+			w.AddComponents(a, &Health{})
+		case 2:
+			w.MarkChanged(a, 3)
+		}
+	}}
+	reader := &stage{run: func(int) {
+		reads = append(reads, seen(w, added()))
+	}}
+	w.AddSystems(writer, reader)
+
+	w.Update(0)
+	w.Update(0)
+	w.Update(0)
+	w.AddComponents(b, &Health{})
+	w.Update(0)
+	w.Update(0)
+
+	wantWrites := [][]ecs.EntityID{nil, nil, nil, {b}, nil}
+	wantReads := [][]ecs.EntityID{{a}, nil, nil, {b}, nil}
+	for frame := range 5 {
+		if !slices.Equal(writes[frame], wantWrites[frame]) {
+			t.Errorf("frame %d: writer saw %v, want %v", frame, writes[frame], wantWrites[frame])
+		}
+		if !slices.Equal(reads[frame], wantReads[frame]) {
+			t.Errorf("frame %d: reader saw %v, want %v", frame, reads[frame], wantReads[frame])
+		}
+	}
+
+	// Replacing a component keeps the tick it was added at
+	w.AddComponents(a, &Health{Current: 1})
+	if got := seen(w, added()); got != nil {
+		t.Errorf("after replacing Health saw %v, want none", got)
+	}
+}
+
+// Systems removed or added during a frame must not make the others forget
+// when they last ran
+func TestChangedWhileSystemsChange(t *testing.T) {
+	w := ecs.NewWorld()
+	e := w.CreateEntity(&Position{}, &Velocity{})
+
+	var reads [][]ecs.EntityID
+	dropped := &stage{run: func(int) {}}
+	remover := &stage{run: func(frame int) {
+		switch frame {
+		case 0:
+			w.RemoveSystems(dropped)
+		case 1:
+			w.AddSystems(&stage{run: func(int) {}})
+		}
+	}}
+	reader := &stage{run: func(int) {
+		reads = append(reads, seen(w, changedPosition()))
+	}}
+	w.AddSystems(remover, dropped, reader)
+
+	for range 3 {
+		w.Update(0)
+	}
+	want := [][]ecs.EntityID{{e}, nil, nil}
+	for frame := range want {
+		if !slices.Equal(reads[frame], want[frame]) {
+			t.Errorf("frame %d: reader saw %v, want %v", frame, reads[frame], want[frame])
+		}
+	}
+}
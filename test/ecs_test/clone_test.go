@@ -0,0 +1,137 @@
+package ecstest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// Follower stores the entity it follows, translated when it moves
+type Follower struct {
+	Leader ecs.EntityID
+}
+
+func (Follower) ID() ecs.ComponentID { return 24 }
+
+func (f *Follower) MapEntities(mapping map[ecs.EntityID]ecs.EntityID) ecs.Component {
+	return &Follower{Leader: mapping[f.Leader]}
+}
+
+func TestClone(t *testing.T) {
+	w := ecs.NewWorld()
+	target := w.CreateEntity()
+	e := w.CreateEntity(&Position{X: 1}, &Inventory{Items: []string{"fuel"}})
+	w.AddPair(e, Likes, target)
+	child := w.CreateEntity()
+	w.SetParent(child, e)
+
+	clone, ok := w.Clone(e)
+	if !ok {
+		t.Fatal("Clone of a live entity failed")
+	}
+	if targets := w.Targets(clone, Likes); !slices.Equal(targets, []ecs.EntityID{target}) {
+		t.Errorf("clone likes %v, want [%d]", targets, target)
+	}
+	if children := w.Children(clone); len(children) != 0 {
+		t.Errorf("clone has children %v", children)
+	}
+
+	// The clone owns its components, Inventory through its Cloner
+	ecs.GetComponent[*Position](w, clone).X = 2
+	inv := ecs.GetComponent[*Inventory](w, clone)
+	inv.Items[0] = "ammo"
+	if pos := ecs.GetComponent[*Position](w, e); pos.X != 1 {
+		t.Errorf("source Position = %v after mutating the clone", *pos)
+	}
+	if inv := ecs.GetComponent[*Inventory](w, e); !slices.Equal(inv.Items, []string{"fuel"}) {
+		t.Errorf("source Inventory = %v after mutating the clone", inv.Items)
+	}
+	if children := w.Children(e); !slices.Equal(children, []ecs.EntityID{child}) {
+		t.Errorf("source children = %v, want [%d]", children, child)
+	}
+
+	if _, ok := w.Clone(12345); ok {
+		t.Error("Clone of a missing entity succeeded")
+	}
+}
+
+func TestMoveEntitiesRemapping(t *testing.T) {
+	src, dst := ecs.NewWorld(), ecs.NewWorld()
+	// Offset the IDs of dst so old and new IDs differ
+	dst.CreateEntity()
+	dst.CreateEntity()
+
+	outsider := src.CreateEntity()
+	root := src.CreateEntity(&Position{})
+	child := src.CreateEntity(&Follower{Leader: root})
+	grandchild := src.CreateEntity(&Follower{Leader: outsider})
+	src.SetParent(child, root)
+	src.SetParent(grandchild, child)
+	src.AddPair(grandchild, Likes, root)
+	src.AddPair(child, Likes, outsider)
+
+	mapping := src.MoveEntities(dst, root, child, grandchild)
+	if len(mapping) != 3 {
+		t.Fatalf("mapping = %v, want 3 entries", mapping)
+	}
+	for old, id := range mapping {
+		if old == id {
+			t.Errorf("entity %d kept its ID", old)
+		}
+		if src.Alive(old) {
+			t.Errorf("entity %d still alive in the source world", old)
+		}
+	}
+
+	// Relationships between moved entities follow them, others are dropped
+	if parent, ok := dst.Parent(mapping[child]); !ok || parent != mapping[root] {
+		t.Errorf("child parent = %d, %v, want %d", parent, ok, mapping[root])
+	}
+	if parent, ok := dst.Parent(mapping[grandchild]); !ok || parent != mapping[child] {
+		t.Errorf("grandchild parent = %d, %v, want %d", parent, ok, mapping[child])
+	}
+	if targets := dst.Targets(mapping[grandchild], Likes); !slices.Equal(targets, []ecs.EntityID{mapping[root]}) {
+		t.Errorf("grandchild likes %v, want [%d]", targets, mapping[root])
+	}
+	if targets := dst.Targets(mapping[child], Likes); len(targets) != 0 {
+		t.Errorf("child likes %v, the outsider stayed behind", targets)
+	}
+
+	// EntityMapper components are given the mapping
+	if f := ecs.GetComponent[*Follower](dst, mapping[child]); f.Leader != mapping[root] {
+		t.Errorf("child follows %d, want %d", f.Leader, mapping[root])
+	}
+	if f := ecs.GetComponent[*Follower](dst, mapping[grandchild]); f.Leader != 0 {
+		t.Errorf("grandchild follows %d, want 0 for an entity that did not move", f.Leader)
+	}
+	if !src.Alive(outsider) {
+		t.Error("outsider destroyed")
+	}
+}
+
+func TestMoveEntitiesOnce(t *testing.T) {
+	src, dst := ecs.NewWorld(), ecs.NewWorld()
+	e := src.CreateEntity(&Position{X: 1}, &Velocity{X: 2})
+
+	mapping := src.MoveEntities(dst, e, e)
+	if len(mapping) != 1 {
+		t.Fatalf("mapping = %v, want one entry", mapping)
+	}
+	if src.Alive(e) {
+		t.Error("entity still alive in the source world")
+	}
+	if rows := ecs.NewFilter(1, 2).Query(dst); len(rows) != 1 {
+		t.Errorf("%d rows in the destination, want 1", len(rows))
+	}
+	entities := 0
+	for _, arch := range dst.ArchetypeChecksums() {
+		entities += arch.Entities
+	}
+	if entities != 1 {
+		t.Errorf("%d entities stored in the destination, want 1", entities)
+	}
+	if pos := ecs.GetComponent[*Position](dst, mapping[e]); pos == nil || pos.X != 1 {
+		t.Errorf("moved position = %v", pos)
+	}
+}
@@ -153,6 +153,44 @@ func BenchmarkEntityCreation(b *testing.B) {
 	}
 }
 
+// Benchmark batch entity creation against one CreateEntity call per entity
+func BenchmarkBatchEntityCreation(b *testing.B) {
+	benchmarks := []struct {
+		name        string
+		entityCount int
+	}{
+		{"Small", 100},
+		{"Medium", 1000},
+		{"Large", 10000},
+	}
+
+	fill := func(i int, row []ecs.Component) {
+		row[0] = Position{X: float64(i)}
+		row[1] = Velocity{X: 1}
+		row[2] = Health{Current: 100, Max: 100}
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name+"_CreateEntity", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				world := ecs.NewWorld()
+				for i := range bm.entityCount {
+					world.CreateEntity(Position{X: float64(i)}, Velocity{X: 1}, Health{Current: 100, Max: 100})
+				}
+			}
+		})
+
+		b.Run(bm.name+"_CreateEntities", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				world := ecs.NewWorld()
+				world.CreateEntities(bm.entityCount, fill, 1, 2, 3)
+			}
+		})
+	}
+}
+
 // Benchmark component access
 func BenchmarkComponentAccess(b *testing.B) {
 	benchmarks := []struct {
@@ -0,0 +1,177 @@
+package ecstest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// Hooked components have their own IDs so their hooks fire in no other test
+type HookA struct{ V int }
+type HookB struct{ V int }
+type HookC struct{ V int }
+
+func (HookA) ID() ecs.ComponentID { return 20 }
+func (HookB) ID() ecs.ComponentID { return 21 }
+func (HookC) ID() ecs.ComponentID { return 22 }
+
+// hookLog records hook calls as "event name value", and whether the
+// component could be read from the world when the hook ran
+type hookLog struct {
+	calls     []string
+	invisible []string
+}
+
+func (l *hookLog) hooks(name string) ecs.ComponentHooks {
+	record := func(event string) ecs.HookFunc {
+		return func(w *ecs.World, entity ecs.EntityID, c ecs.Component) {
+			call := fmt.Sprintf("%s %s %v", event, name, c)
+			l.calls = append(l.calls, call)
+			components, _ := w.Components(entity)
+			if !slices.ContainsFunc(components, func(held ecs.Component) bool { return held == c }) {
+				l.invisible = append(l.invisible, call)
+			}
+		}
+	}
+	return ecs.ComponentHooks{OnAdd: record("add"), OnSet: record("set"), OnRemove: record("remove")}
+}
+
+func (l *hookLog) take(t *testing.T, step string, want ...string) {
+	t.Helper()
+	if !slices.Equal(l.calls, want) {
+		t.Errorf("%s: hooks %q, want %q", step, l.calls, want)
+	}
+	if len(l.invisible) > 0 {
+		t.Errorf("%s: component not stored when its hook ran: %q", step, l.invisible)
+	}
+	l.calls, l.invisible = nil, nil
+}
+
+func TestHookOrder(t *testing.T) {
+	log := &hookLog{}
+	ecs.RegisterComponentHooks(20, log.hooks("A"))
+	ecs.RegisterComponentHooks(21, log.hooks("B"))
+	ecs.RegisterComponentHooks(22, log.hooks("C"))
+	defer func() {
+		for id := ecs.ComponentID(20); id <= 22; id++ {
+			ecs.RegisterComponentHooks(id, ecs.ComponentHooks{})
+		}
+	}()
+
+	w := ecs.NewWorld()
+
+	// OnAdd in argument order, once every component is stored
+	e := w.CreateEntity(&HookB{1}, &HookA{1})
+	log.take(t, "create", "add B &{1}", "add A &{1}")
+
+	// Added components fire OnAdd in argument order, replaced ones OnSet
+	w.AddComponents(e, &HookC{1}, &HookA{2})
+	log.take(t, "add", "add C &{1}", "set A &{2}")
+
+	w.SetComponent(e, &HookB{2})
+	log.take(t, "set", "set B &{2}")
+
+	// SetComponent does not add missing components
+	other := w.CreateEntity(&HookA{9})
+	log.take(t, "create other", "add A &{9}")
+	if w.SetComponent(other, &HookC{9}) {
+		t.Error("SetComponent added a component")
+	}
+	log.take(t, "set missing")
+
+	// OnRemove in ascending ID order, before the components are detached
+	w.RemoveComponents(e, 22, 20)
+	log.take(t, "remove", "remove A &{2}", "remove C &{1}")
+
+	w.AddComponents(e, &HookC{3}, &HookA{3})
+	log.take(t, "re-add", "add C &{3}", "add A &{3}")
+	w.DestroyEntity(e)
+	log.take(t, "destroy", "remove A &{3}", "remove B &{2}", "remove C &{3}")
+}
+
+// Hooks run without locks held and may change the world; the changes apply
+// before the remaining hooks of the operation.
+func TestHooksModifyWorld(t *testing.T) {
+	var seen []bool
+	ecs.RegisterComponentHooks(20, ecs.ComponentHooks{
+		OnAdd: func(w *ecs.World, entity ecs.EntityID, _ ecs.Component) {
+			w.AddComponents(entity, &Health{Current: 1})
+		},
+	})
+	ecs.RegisterComponentHooks(21, ecs.ComponentHooks{
+		OnAdd: func(w *ecs.World, entity ecs.EntityID, _ ecs.Component) {
+			components, _ := w.Components(entity)
+			seen = append(seen, slices.ContainsFunc(components, func(c ecs.Component) bool {
+				_, ok := c.(*Health)
+				return ok
+			}))
+		},
+	})
+	defer ecs.RegisterComponentHooks(20, ecs.ComponentHooks{})
+	defer ecs.RegisterComponentHooks(21, ecs.ComponentHooks{})
+
+	w := ecs.NewWorld()
+	e := w.CreateEntity(&HookA{}, &HookB{})
+	if !slices.Equal(seen, []bool{true}) {
+		t.Errorf("B saw Health added by A's hook: %v, want [true]", seen)
+	}
+	if components, _ := w.Components(e); len(components) != 3 {
+		t.Errorf("entity has %d components, want 3", len(components))
+	}
+}
+
+func TestCreateEntityFiresOnAddOnce(t *testing.T) {
+	log := &hookLog{}
+	ecs.RegisterComponentHooks(20, log.hooks("A"))
+	defer ecs.RegisterComponentHooks(20, ecs.ComponentHooks{})
+
+	// The last component given for an ID is kept
+	w := ecs.NewWorld()
+	e := w.CreateEntity(&HookA{1}, &Position{}, &HookA{2})
+	log.take(t, "create", "add A &{2}")
+	w.AddComponents(e, &HookB{}, &HookA{3}, &HookA{4})
+	log.take(t, "add", "set A &{4}")
+
+	components, _ := w.Components(e)
+	if len(components) != 3 || *components[1].(*HookA) != (HookA{4}) {
+		t.Errorf("components = %v", components)
+	}
+}
+
+// Observers are notified from what the entity holds once OnRemove hooks ran
+func TestObserversSeeChangesOfOnRemoveHooks(t *testing.T) {
+	ecs.RegisterComponentHooks(20, ecs.ComponentHooks{
+		OnRemove: func(w *ecs.World, entity ecs.EntityID, _ ecs.Component) {
+			w.RemoveComponents(entity, 1)
+		},
+	})
+	ecs.RegisterComponentHooks(21, ecs.ComponentHooks{
+		OnRemove: func(w *ecs.World, entity ecs.EntityID, _ ecs.Component) {
+			w.AddComponents(entity, &Position{})
+		},
+	})
+	defer ecs.RegisterComponentHooks(20, ecs.ComponentHooks{})
+	defer ecs.RegisterComponentHooks(21, ecs.ComponentHooks{})
+
+	w := ecs.NewWorld()
+	// Position without HookA
+	filter := ecs.NewFilter(1)
+	filter.Without(20)
+	log := &observerLog{}
+	w.Observe(filter, log.record("enter"), log.record("exit"))
+
+	// Removing HookA also removes Position, so the entity never matches
+	e := w.CreateEntity(&HookA{}, &Position{})
+	w.RemoveComponents(e, 20)
+	log.take(t, "remove")
+	if components, _ := w.Components(e); len(components) != 0 {
+		t.Errorf("components = %v, want none", components)
+	}
+
+	// Destroying the entity gives it a Position first, which must exit again
+	e = w.CreateEntity(&HookB{})
+	w.DestroyEntity(e)
+	log.take(t, "destroy", fmt.Sprintf("enter %d true", e), fmt.Sprintf("exit %d true", e))
+}
@@ -0,0 +1,114 @@
+package ecstest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// observerLog records observer calls as "enter e" and "exit e", along with
+// whether the entity had Position when the callback ran
+type observerLog struct {
+	calls []string
+}
+
+func (l *observerLog) record(event string) ecs.ObserverFunc {
+	return func(w *ecs.World, entity ecs.EntityID) {
+		has := ecs.GetComponent[*Position](w, entity) != nil
+		l.calls = append(l.calls, fmt.Sprintf("%s %d %v", event, entity, has))
+	}
+}
+
+func (l *observerLog) take(t *testing.T, step string, want ...string) {
+	t.Helper()
+	if !slices.Equal(l.calls, want) {
+		t.Errorf("%s: observed %q, want %q", step, l.calls, want)
+	}
+	l.calls = nil
+}
+
+// observe watches entities with Position and without Health
+func observe(w *ecs.World) (*observerLog, *ecs.Observer) {
+	log := &observerLog{}
+	filter := ecs.NewFilter(1)
+	filter.Without(3)
+	return log, w.Observe(filter, log.record("enter"), log.record("exit"))
+}
+
+func TestObserveExistingEntities(t *testing.T) {
+	w := ecs.NewWorld()
+	a := w.CreateEntity(&Position{})
+	w.CreateEntity(&Position{}, &Health{})
+	w.CreateEntity(&Velocity{})
+	b := w.CreateEntity(&Position{}, &Velocity{})
+
+	log, _ := observe(w)
+	slices.Sort(log.calls)
+	log.take(t, "observe", fmt.Sprintf("enter %d true", a), fmt.Sprintf("enter %d true", b))
+
+	// Without onEnter there is nothing to pass existing entities to
+	filter := ecs.NewFilter(1)
+	w.Observe(filter, nil, log.record("exit"))
+	log.take(t, "observe exits only")
+}
+
+func TestObserveCreateAndDestroy(t *testing.T) {
+	w := ecs.NewWorld()
+	log, _ := observe(w)
+
+	e := w.CreateEntity(&Position{}, &Velocity{})
+	log.take(t, "create", fmt.Sprintf("enter %d true", e))
+	w.CreateEntity(&Position{}, &Health{})
+	log.take(t, "create excluded")
+
+	ids := w.CreateEntities(2, func(_ int, row []ecs.Component) {
+		row[0] = &Position{}
+	}, 1)
+	log.take(t, "create batch", fmt.Sprintf("enter %d true", ids[0]), fmt.Sprintf("enter %d true", ids[1]))
+
+	// onExit still sees the components of a destroyed entity
+	w.DestroyEntity(e)
+	log.take(t, "destroy", fmt.Sprintf("exit %d true", e))
+	w.DestroyEntity(e)
+	log.take(t, "destroy twice")
+}
+
+func TestObserveMigrations(t *testing.T) {
+	w := ecs.NewWorld()
+	e := w.CreateEntity(&Velocity{})
+	log, _ := observe(w)
+	log.take(t, "observe")
+
+	w.AddComponents(e, &Position{})
+	log.take(t, "gain included", fmt.Sprintf("enter %d true", e))
+
+	// Staying inside the filter is not an enter or exit
+	w.AddComponents(e, &Sprite{}, &Position{X: 1})
+	w.RemoveComponents(e, 4)
+	w.SetComponent(e, &Position{X: 2})
+	log.take(t, "stay matching")
+
+	w.AddComponents(e, &Health{})
+	log.take(t, "gain excluded", fmt.Sprintf("exit %d true", e))
+	w.RemoveComponents(e, 3)
+	log.take(t, "lose excluded", fmt.Sprintf("enter %d true", e))
+
+	// onExit runs before Position is removed, onEnter after it is added
+	w.RemoveComponents(e, 1)
+	log.take(t, "lose included", fmt.Sprintf("exit %d true", e))
+	w.AddComponents(e, &Position{})
+	log.take(t, "regain included", fmt.Sprintf("enter %d true", e))
+}
+
+func TestUnobserve(t *testing.T) {
+	w := ecs.NewWorld()
+	log, observer := observe(w)
+	w.Unobserve(observer)
+
+	e := w.CreateEntity(&Position{})
+	w.AddComponents(e, &Health{})
+	w.DestroyEntity(e)
+	log.take(t, "after Unobserve")
+}
@@ -0,0 +1,199 @@
+package ecstest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// Inventory holds a slice, so it copies itself instead of sharing it
+type Inventory struct {
+	Items []string
+}
+
+func (Inventory) ID() ecs.ComponentID { return 23 }
+
+func (i *Inventory) Clone() ecs.Component {
+	return &Inventory{Items: slices.Clone(i.Items)}
+}
+
+func newShip() *ecs.Prefab {
+	turret := ecs.NewPrefab("turret", &Position{X: 1}).
+		WithChildren(ecs.NewPrefab("barrel", &Position{X: 2}))
+	return ecs.NewPrefab("ship", &Position{}, &Health{Current: 10, Max: 10}, &Inventory{Items: []string{"fuel"}}).
+		WithChildren(turret, ecs.NewPrefab("engine", &Velocity{}))
+}
+
+// checkPrefab fails when instances changed the template components of ship
+func checkPrefab(t *testing.T, ship *ecs.Prefab) {
+	t.Helper()
+	if pos := ship.Components[0].(*Position); *pos != (Position{}) {
+		t.Errorf("prefab Position = %v", *pos)
+	}
+	if health := ship.Components[1].(*Health); *health != (Health{Current: 10, Max: 10}) {
+		t.Errorf("prefab Health = %v", *health)
+	}
+	if inv := ship.Components[2].(*Inventory); !slices.Equal(inv.Items, []string{"fuel"}) {
+		t.Errorf("prefab Inventory = %v", inv.Items)
+	}
+	if turret := ship.Children[0].Components[0].(*Position); *turret != (Position{X: 1}) {
+		t.Errorf("prefab turret Position = %v", *turret)
+	}
+}
+
+// checkChildren checks the turret, barrel and engine created for a ship
+func checkChildren(t *testing.T, w *ecs.World, ship ecs.EntityID) {
+	t.Helper()
+	children := w.Sources(ecs.ChildOf, ship)
+	if len(children) != 2 {
+		t.Fatalf("ship has %d children, want 2", len(children))
+	}
+	var turret ecs.EntityID
+	for _, child := range children {
+		if pos := ecs.GetComponent[*Position](w, child); pos != nil {
+			turret = child
+			if pos.X != 1 {
+				t.Errorf("turret Position = %v", *pos)
+			}
+		} else if ecs.GetComponent[*Velocity](w, child) == nil {
+			t.Errorf("child %d is neither turret nor engine", child)
+		}
+	}
+	barrels := w.Sources(ecs.ChildOf, turret)
+	if len(barrels) != 1 {
+		t.Fatalf("turret has %d children, want 1", len(barrels))
+	}
+	if pos := ecs.GetComponent[*Position](w, barrels[0]); pos == nil || pos.X != 2 {
+		t.Errorf("barrel Position = %v", pos)
+	}
+	if targets := w.Targets(barrels[0], ecs.ChildOf); !slices.Equal(targets, []ecs.EntityID{turret}) {
+		t.Errorf("barrel is ChildOf %v, want [%d]", targets, turret)
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	w := ecs.NewWorld()
+	ship := newShip()
+
+	override := &Health{Current: 5, Max: 10}
+	e := w.Instantiate(ship, override, &Sprite{TextureID: 7})
+
+	if got := ecs.GetComponent[*Health](w, e); got != override {
+		t.Errorf("Health = %v, want the override", got)
+	}
+	if got := ecs.GetComponent[*Sprite](w, e); got == nil || got.TextureID != 7 {
+		t.Errorf("Sprite = %v, want the added override", got)
+	}
+	components, _ := w.Components(e)
+	if len(components) != 4 {
+		t.Errorf("ship has %d components, want 4", len(components))
+	}
+	checkChildren(t, w, e)
+
+	// Instances get their own copies, also of the slice inside Inventory
+	pos := ecs.GetComponent[*Position](w, e)
+	if pos == ship.Components[0] {
+		t.Fatal("instance shares Position with the prefab")
+	}
+	pos.X = 100
+	inv := ecs.GetComponent[*Inventory](w, e)
+	inv.Items[0] = "ammo"
+	inv.Items = append(inv.Items, "spares")
+	for _, child := range w.Sources(ecs.ChildOf, e) {
+		if pos := ecs.GetComponent[*Position](w, child); pos != nil {
+			pos.X = 100
+		}
+	}
+	checkPrefab(t, ship)
+
+	// A second instance starts from the prefab again
+	other := w.Instantiate(ship)
+	if pos := ecs.GetComponent[*Position](w, other); pos.X != 0 {
+		t.Errorf("second instance Position = %v", *pos)
+	}
+	if health := ecs.GetComponent[*Health](w, other); health.Current != 10 {
+		t.Errorf("second instance Health = %v", *health)
+	}
+	if ecs.GetComponent[*Sprite](w, other) != nil {
+		t.Error("override of the first instance leaked into the second")
+	}
+	checkChildren(t, w, other)
+}
+
+func TestInstantiateN(t *testing.T) {
+	w := ecs.NewWorld()
+	ship := newShip()
+
+	entities := w.InstantiateN(ship, 3, func(i int, row []ecs.Component) {
+		row[0].(*Position).X = float64(i)
+		row[2].(*Inventory).Items[0] = "cargo"
+		if i == 2 {
+			row[1] = &Health{Current: 1, Max: 1}
+		}
+	})
+	if len(entities) != 3 {
+		t.Fatalf("%d entities, want 3", len(entities))
+	}
+
+	seen := map[*Position]bool{}
+	for i, e := range entities {
+		pos := ecs.GetComponent[*Position](w, e)
+		if pos.X != float64(i) {
+			t.Errorf("entity %d Position = %v", i, *pos)
+		}
+		if seen[pos] {
+			t.Errorf("entity %d shares Position with another instance", i)
+		}
+		seen[pos] = true
+
+		wantHealth := 10.0
+		if i == 2 {
+			wantHealth = 1
+		}
+		if health := ecs.GetComponent[*Health](w, e); health.Current != wantHealth {
+			t.Errorf("entity %d Health = %v, want %v", i, *health, wantHealth)
+		}
+		if inv := ecs.GetComponent[*Inventory](w, e); !slices.Equal(inv.Items, []string{"cargo"}) {
+			t.Errorf("entity %d Inventory = %v", i, inv.Items)
+		}
+		checkChildren(t, w, e)
+	}
+	checkPrefab(t, ship)
+
+	// fill may be nil
+	for _, e := range w.InstantiateN(ship, 2, nil) {
+		if health := ecs.GetComponent[*Health](w, e); health.Current != 10 {
+			t.Errorf("Health = %v, want the prefab's", *health)
+		}
+		checkChildren(t, w, e)
+	}
+}
+
+func TestInstantiateNRejectsPairs(t *testing.T) {
+	w := ecs.NewWorld()
+	parent := w.CreateEntity()
+	child := w.CreateEntity(&Position{})
+	w.AddPair(child, ecs.ChildOf, parent)
+	components, _ := w.Components(child)
+	sibling := ecs.NewPrefab("sibling", components...)
+
+	func() {
+		defer func() {
+			if msg, _ := recover().(string); !strings.Contains(msg, "use Instantiate") {
+				t.Errorf("InstantiateN panicked with %q", msg)
+			}
+		}()
+		w.InstantiateN(sibling, 2, nil)
+	}()
+	if children := w.Sources(ecs.ChildOf, parent); !slices.Equal(children, []ecs.EntityID{child}) {
+		t.Fatalf("children after InstantiateN = %v, want [%d]", children, child)
+	}
+
+	// Instantiate keeps the relationship
+	e := w.Instantiate(sibling)
+	if got, ok := w.Parent(e); !ok || got != parent {
+		t.Errorf("instance parent = %d, %v, want %d", got, ok, parent)
+	}
+}
@@ -0,0 +1,108 @@
+package ecstest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// querying iterates the entities with a Position, once through an iterator
+// and once through the query cache
+type querying struct{ w *ecs.World }
+
+func (s *querying) Update(float64) {
+	filter := ecs.NewFilter(1)
+	for it := filter.Iterator(s.w); it.Next(); {
+	}
+	filter.Query(s.w)
+}
+
+type idle struct{}
+
+func (*idle) Update(float64) {}
+
+func TestProfiler(t *testing.T) {
+	w := ecs.NewWorld()
+	for range 4 {
+		w.CreateEntity(&Position{})
+	}
+	w.CreateEntity(&Velocity{})
+	w.AddSystems(&querying{w}, &idle{})
+
+	w.Update(0)
+	if stats := w.Stats(); len(stats.Frames) != 0 || len(stats.Systems) != 0 {
+		t.Fatalf("stats recorded without profiling: %+v", stats)
+	}
+
+	w.EnableProfiling(3)
+	for range 5 {
+		w.Update(0)
+	}
+
+	// The ring buffer keeps the last 3 of the 5 frames, oldest first
+	stats := w.Stats()
+	if len(stats.Frames) != 3 {
+		t.Fatalf("%d frames, want 3", len(stats.Frames))
+	}
+	for i, frame := range stats.Frames {
+		if frame.Frame != uint64(i+2) {
+			t.Errorf("frame %d is number %d, want %d", i, frame.Frame, i+2)
+		}
+		if len(frame.Systems) != 2 || frame.Systems[0].Rows != 8 || frame.Systems[1].Rows != 0 {
+			t.Errorf("frame %d systems: %+v", i, frame.Systems)
+		}
+	}
+
+	if len(stats.Systems) != 2 {
+		t.Fatalf("%d system summaries, want 2", len(stats.Systems))
+	}
+	for i, want := range []ecs.SystemSummary{
+		{Name: "ecstest.querying", Calls: 3, Rows: 24},
+		{Name: "ecstest.idle", Calls: 3},
+	} {
+		got := stats.Systems[i]
+		if got.Name != want.Name || got.Calls != want.Calls || got.Rows != want.Rows {
+			t.Errorf("summary %d = %+v, want %+v", i, got, want)
+		}
+		if got.Max > got.Total || got.Avg() > got.Max {
+			t.Errorf("summary %d times: avg %v, max %v, total %v", i, got.Avg(), got.Max, got.Total)
+		}
+	}
+
+	out := stats.String()
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("String has %d lines, want 4:\n%s", len(lines), out)
+	}
+	if !strings.HasPrefix(lines[0], "frames: 3, avg frame: ") {
+		t.Errorf("summary line %q", lines[0])
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "SYSTEM CALLS AVG MAX TOTAL ROWS" {
+		t.Errorf("header %q", lines[1])
+	}
+	for i, want := range [][2]string{{"ecstest.querying", "8"}, {"ecstest.idle", "0"}} {
+		fields := strings.Fields(lines[2+i])
+		if len(fields) != 6 || fields[0] != want[0] || fields[1] != "3" || fields[5] != want[1] {
+			t.Errorf("row %q, want %s called 3 times with %s rows", lines[2+i], want[0], want[1])
+		}
+	}
+
+	// Enabling again starts over, disabling drops everything
+	w.EnableProfiling(3)
+	w.Update(0)
+	if frames := w.Stats().Frames; len(frames) != 1 || frames[0].Frame != 0 {
+		t.Errorf("frames after enabling again: %+v", frames)
+	}
+	w.DisableProfiling()
+	w.Update(0)
+	if stats := w.Stats(); len(stats.Frames) != 0 {
+		t.Errorf("%d frames after DisableProfiling", len(stats.Frames))
+	}
+	w.EnableProfiling(2)
+	w.EnableProfiling(0)
+	w.Update(0)
+	if stats := w.Stats(); len(stats.Frames) != 0 {
+		t.Errorf("%d frames after EnableProfiling(0)", len(stats.Frames))
+	}
+}
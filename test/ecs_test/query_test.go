@@ -0,0 +1,150 @@
+package ecstest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// A hash mixing the component sets gives these two filters the same value,
+// the cache must still return each its own rows.
+func TestQueryCacheKeepsFiltersApart(t *testing.T) {
+	w := ecs.NewWorld()
+	w.CreateEntity(&Position{}, &Velocity{})
+	w.CreateEntity(&Position{})
+
+	optional := ecs.NewFilter(1)
+	optional.Optional(0)
+	if got := len(optional.Query(w)); got != 2 {
+		t.Fatalf("optional query: %d rows, want 2", got)
+	}
+
+	rows := ecs.NewFilter(1, 2).Query(w)
+	if len(rows) != 1 {
+		t.Fatalf("query: %d rows, want 1", len(rows))
+	}
+	if _, ok := rows[0][1].(*Velocity); !ok {
+		t.Errorf("second column is %T, want *Velocity", rows[0][1])
+	}
+}
+
+// Tag0 has the lowest ID, which words of a set holding nothing else encode
+// like an empty set
+type Tag0 struct{}
+
+func (Tag0) ID() ecs.ComponentID { return 0 }
+
+// Where(Has(), Has(0)) and Where(Has(1)) hash alike, the cache must still
+// keep filters with only terms apart.
+func TestQueryCacheKeepsTermsApart(t *testing.T) {
+	w := ecs.NewWorld()
+	w.CreateEntity(&Tag0{})
+	w.CreateEntity(&Tag0{}, &Velocity{})
+	w.CreateEntity(&Position{})
+
+	tags := ecs.NewFilter()
+	tags.Where(ecs.Has(), ecs.Has(0))
+	if got := len(tags.Query(w)); got != 2 {
+		t.Fatalf("Where(Has(), Has(0)): %d rows, want 2", got)
+	}
+	positions := ecs.NewFilter()
+	positions.Where(ecs.Has(1))
+	if got := len(positions.Query(w)); got != 1 {
+		t.Errorf("Where(Has(1)): %d rows, want 1", got)
+	}
+
+	and := ecs.NewFilter()
+	and.Where(ecs.And(ecs.Has(0), ecs.Has(2)))
+	or := ecs.NewFilter()
+	or.Where(ecs.Or(ecs.Has(0), ecs.Has(2)))
+	if got := len(and.Query(w)); got != 1 {
+		t.Errorf("And: %d rows, want 1", got)
+	}
+	if got := len(or.Query(w)); got != 2 {
+		t.Errorf("Or: %d rows, want 2", got)
+	}
+}
+
+func TestOptionalColumns(t *testing.T) {
+	w := ecs.NewWorld()
+	both := w.CreateEntity(&Position{X: 1}, &Velocity{X: 2}, &Health{Current: 3})
+	bare := w.CreateEntity(&Position{X: 4})
+	w.CreateEntity(&Velocity{}, &Health{})
+
+	filter := ecs.NewFilter(1)
+	filter.Optional(3, 2)
+	rows := map[ecs.EntityID][]ecs.Component{}
+	for it := filter.Iterator(w); it.Next(); {
+		rows[it.Entity()] = slices.Clone(it.Row())
+	}
+	if len(rows) != 2 {
+		t.Fatalf("%d rows, want 2", len(rows))
+	}
+
+	// Included components first, then optional ones by ID
+	row := rows[both]
+	if len(row) != 3 || *row[0].(*Position) != (Position{X: 1}) ||
+		*row[1].(*Velocity) != (Velocity{X: 2}) || *row[2].(*Health) != (Health{Current: 3}) {
+		t.Errorf("row with every component = %v", row)
+	}
+	row = rows[bare]
+	if len(row) != 3 || *row[0].(*Position) != (Position{X: 4}) || row[1] != nil || row[2] != nil {
+		t.Errorf("row without optional components = %v, want nil columns", row)
+	}
+
+	if got := len(filter.Query(w)); got != 2 {
+		t.Errorf("Query: %d rows, want 2", got)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	w := ecs.NewWorld()
+	p := w.CreateEntity(&Position{})
+	pv := w.CreateEntity(&Position{}, &Velocity{})
+	ph := w.CreateEntity(&Position{}, &Health{})
+	vh := w.CreateEntity(&Velocity{}, &Health{})
+	s := w.CreateEntity(&Sprite{})
+
+	tests := []struct {
+		name  string
+		terms []ecs.Term
+		want  []ecs.EntityID
+	}{
+		{"AnyOf", []ecs.Term{ecs.AnyOf(2, 3)}, []ecs.EntityID{pv, ph, vh}},
+		{"Not", []ecs.Term{ecs.Not(ecs.Has(1))}, []ecs.EntityID{vh, s}},
+		{"Not AnyOf", []ecs.Term{ecs.Not(ecs.AnyOf(1, 4))}, []ecs.EntityID{vh}},
+		{"Or", []ecs.Term{ecs.Or(ecs.Has(1, 2), ecs.Has(4))}, []ecs.EntityID{pv, s}},
+		{"And", []ecs.Term{ecs.And(ecs.AnyOf(1, 2), ecs.Not(ecs.Has(3)))}, []ecs.EntityID{p, pv}},
+		{"Or of And", []ecs.Term{ecs.Or(ecs.And(ecs.Has(1), ecs.Not(ecs.AnyOf(2, 3))), ecs.Has(2, 3))}, []ecs.EntityID{p, vh}},
+		{"several terms", []ecs.Term{ecs.AnyOf(1, 2), ecs.AnyOf(3, 4)}, []ecs.EntityID{ph, vh}},
+		{"empty Or", []ecs.Term{ecs.Or()}, nil},
+		{"empty And", []ecs.Term{ecs.And()}, []ecs.EntityID{p, pv, ph, vh, s}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := ecs.NewFilter()
+			filter.Where(tt.terms...)
+			var got []ecs.EntityID
+			for it := filter.Iterator(w); it.Next(); {
+				got = append(got, it.Entity())
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("matched %v, want %v", got, tt.want)
+			}
+			if rows := filter.Query(w); len(rows) != len(tt.want) {
+				t.Errorf("Query: %d rows, want %d", len(rows), len(tt.want))
+			}
+		})
+	}
+
+	// Terms come on top of included and excluded components
+	filter := ecs.NewFilter(1)
+	filter.Without(3)
+	filter.Where(ecs.Not(ecs.Has(2)))
+	if rows := filter.Query(w); len(rows) != 1 {
+		t.Errorf("Position without Health or Velocity: %d rows, want 1", len(rows))
+	}
+}
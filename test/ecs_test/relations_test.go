@@ -0,0 +1,165 @@
+package ecstest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// Thirteenth takes the ID the 13th 1 << iota component would get, the first
+// one reserved for relationship pairs.
+type Thirteenth struct{}
+
+func (Thirteenth) ID() ecs.ComponentID { return 1 << 12 }
+
+func mustPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Errorf("%s did not panic", name)
+			return
+		}
+		if msg, _ := r.(string); !strings.Contains(msg, "PairIDBase") {
+			t.Errorf("%s panicked with %v", name, r)
+		}
+	}()
+	f()
+}
+
+func TestComponentIDsBelowPairs(t *testing.T) {
+	if id := (Thirteenth{}).ID(); id != ecs.PairIDBase {
+		t.Fatalf("Thirteenth has ID %d, want PairIDBase", id)
+	}
+
+	w := ecs.NewWorld()
+	parent := w.CreateEntity()
+	child := w.CreateEntity()
+	w.AddPair(child, ecs.ChildOf, parent)
+
+	mustPanic(t, "RegisterComponentType", func() {
+		ecs.RegisterComponentType[*Thirteenth](ecs.PairIDBase)
+	})
+	mustPanic(t, "CreateEntity", func() {
+		w.CreateEntity(&Thirteenth{})
+	})
+	mustPanic(t, "AddComponents", func() {
+		w.AddComponents(parent, &Thirteenth{})
+	})
+	mustPanic(t, "Instantiate", func() {
+		w.Instantiate(ecs.NewPrefab("thirteenth", &Thirteenth{}))
+	})
+	mustPanic(t, "InstantiateN", func() {
+		w.InstantiateN(ecs.NewPrefab("thirteenth", &Thirteenth{}), 1, nil)
+	})
+
+	if children := w.Sources(ecs.ChildOf, parent); len(children) != 1 || children[0] != child {
+		t.Errorf("children = %v, want [%d]", children, child)
+	}
+}
+
+// Relations registered by these tests, on top of ChildOf
+const (
+	Owns  ecs.RelationID = 10
+	Likes ecs.RelationID = 11
+)
+
+func init() {
+	ecs.RegisterRelation(Owns, ecs.Delete)
+	ecs.RegisterRelation(Likes, ecs.Orphan)
+}
+
+func TestDeleteCascade(t *testing.T) {
+	w := ecs.NewWorld()
+	root := w.CreateEntity(&Position{})
+	child := w.CreateEntity(&Position{})
+	grandchild := w.CreateEntity(&Velocity{})
+	item := w.CreateEntity(&Health{})
+	other := w.CreateEntity(&Position{})
+	w.AddPair(child, ecs.ChildOf, root)
+	w.AddPair(grandchild, ecs.ChildOf, child)
+	w.AddPair(item, Owns, grandchild)
+	w.AddPair(other, Likes, grandchild)
+
+	w.DestroyEntity(root)
+	for _, e := range []ecs.EntityID{root, child, grandchild, item} {
+		if w.Alive(e) {
+			t.Errorf("entity %d survived the deletion of its ancestor", e)
+		}
+	}
+	if !w.Alive(other) {
+		t.Fatal("entity related through an Orphan relation was destroyed")
+	}
+	if components, _ := w.Components(other); len(components) != 1 {
+		t.Errorf("survivor components = %v, want only its Position", components)
+	}
+	if got := len(ecs.NewFilter(1).Query(w)); got != 1 {
+		t.Errorf("%d Position rows, want 1", got)
+	}
+}
+
+func TestOrphanCascade(t *testing.T) {
+	w := ecs.NewWorld()
+	star := w.CreateEntity(&Position{})
+	other := w.CreateEntity(&Position{})
+	fans := []ecs.EntityID{w.CreateEntity(&Velocity{}), w.CreateEntity(&Velocity{}, &Health{})}
+	for _, fan := range fans {
+		w.AddPair(fan, Likes, star)
+		w.AddPair(fan, Likes, other)
+	}
+
+	w.DestroyEntity(star)
+	for _, fan := range fans {
+		if !w.Alive(fan) {
+			t.Fatalf("fan %d destroyed with its target", fan)
+		}
+		if targets := w.Targets(fan, Likes); !slices.Equal(targets, []ecs.EntityID{other}) {
+			t.Errorf("fan %d likes %v, want [%d]", fan, targets, other)
+		}
+	}
+	if sources := w.Sources(Likes, star); len(sources) != 0 {
+		t.Errorf("destroyed target still has sources %v", sources)
+	}
+
+	// The released pair ID is handed to the next pair without stale sources
+	newcomer := w.CreateEntity()
+	w.AddPair(fans[0], Owns, newcomer)
+	if sources := w.Sources(Owns, newcomer); !slices.Equal(sources, fans[:1]) {
+		t.Errorf("sources of a new pair = %v, want %v", sources, fans[:1])
+	}
+}
+
+func TestRemovePair(t *testing.T) {
+	w := ecs.NewWorld()
+	a := w.CreateEntity(&Position{})
+	b := w.CreateEntity()
+	c := w.CreateEntity()
+	w.AddPair(a, Likes, b)
+	w.AddPair(a, Likes, c)
+	w.AddPair(a, Owns, b)
+
+	if !w.RemovePair(a, Likes, b) {
+		t.Fatal("RemovePair reported false")
+	}
+	if targets := w.Targets(a, Likes); !slices.Equal(targets, []ecs.EntityID{c}) {
+		t.Errorf("likes %v, want [%d]", targets, c)
+	}
+	if targets := w.Targets(a, Owns); !slices.Equal(targets, []ecs.EntityID{b}) {
+		t.Errorf("owns %v, want [%d]", targets, b)
+	}
+	if sources := w.Sources(Likes, b); len(sources) != 0 {
+		t.Errorf("b is still liked by %v", sources)
+	}
+	if w.RemovePair(a, Likes, w.CreateEntity()) {
+		t.Error("RemovePair of a pair never used reported true")
+	}
+
+	// Without the pair, a survives b under a Delete relation
+	w.RemovePair(a, Owns, b)
+	w.DestroyEntity(b)
+	if !w.Alive(a) {
+		t.Error("entity destroyed through a removed pair")
+	}
+}
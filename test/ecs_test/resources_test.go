@@ -0,0 +1,97 @@
+package ecstest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+type Gravity struct{ Y float64 }
+
+type Score struct{ Points int }
+
+func TestResources(t *testing.T) {
+	w := ecs.NewWorld()
+	if g := ecs.GetResource[Gravity](w); g != nil {
+		t.Fatalf("GetResource before SetResource = %v", g)
+	}
+
+	gravity := &Gravity{Y: -9.8}
+	ecs.SetResource(w, gravity)
+	ecs.SetResource(w, &Score{Points: 3})
+	if g := ecs.GetResource[Gravity](w); g != gravity {
+		t.Errorf("GetResource = %v, want the stored pointer", g)
+	}
+	ecs.GetResource[Score](w).Points++
+	if s := ecs.GetResource[Score](w); s.Points != 4 {
+		t.Errorf("Score = %v, want 4 points", *s)
+	}
+
+	moon := &Gravity{Y: -1.6}
+	ecs.SetResource(w, moon)
+	if g := ecs.GetResource[Gravity](w); g != moon {
+		t.Errorf("GetResource after replacing = %v, want %v", g, moon)
+	}
+
+	ecs.RemoveResource[Gravity](w)
+	ecs.RemoveResource[Gravity](w)
+	if g := ecs.GetResource[Gravity](w); g != nil {
+		t.Errorf("GetResource after RemoveResource = %v", g)
+	}
+	if s := ecs.GetResource[Score](w); s == nil {
+		t.Error("RemoveResource removed another type")
+	}
+	if other := ecs.GetResource[Score](ecs.NewWorld()); other != nil {
+		t.Errorf("resource leaked to another world: %v", other)
+	}
+}
+
+func TestEventsLastOneFrame(t *testing.T) {
+	w := ecs.NewWorld()
+	events := ecs.GetEvents[int](w)
+	if ecs.GetEvents[int](w) != events {
+		t.Fatal("GetEvents returned another queue")
+	}
+	if ecs.GetEvents[string](w) == nil {
+		t.Fatal("no queue for another event type")
+	}
+
+	early := events.Reader()
+	events.Send(1, 2)
+	if got := early.Read(); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("same frame: %v, want [1 2]", got)
+	}
+	if got := early.Read(); got != nil {
+		t.Errorf("read again: %v, want none", got)
+	}
+
+	// Readers keep their own cursors
+	late := events.Reader()
+	w.Update(0)
+	events.Send(3)
+	if got := late.Read(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("frame after: %v, want [1 2 3]", got)
+	}
+	if got := early.Read(); !slices.Equal(got, []int{3}) {
+		t.Errorf("frame after, reader that saw [1 2]: %v, want [3]", got)
+	}
+	if got := events.Reader().Read(); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("new reader in the frame after: %v, want [1 2 3]", got)
+	}
+
+	// Two swaps after being sent, events are gone even for readers that
+	// never got to them
+	idle := events.Reader()
+	w.Update(0)
+	if got := idle.Read(); !slices.Equal(got, []int{3}) {
+		t.Errorf("second frame after: %v, want [3]", got)
+	}
+	w.Update(0)
+	if got := events.Reader().Read(); got != nil {
+		t.Errorf("third frame after: %v, want none", got)
+	}
+	if got := early.Read(); got != nil {
+		t.Errorf("reader after the events were dropped: %v, want none", got)
+	}
+}
@@ -0,0 +1,66 @@
+package ecstest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// lifecycle records Init and Shutdown calls of named systems
+type lifecycle struct {
+	calls []string
+}
+
+type managed struct {
+	name string
+	log  *lifecycle
+}
+
+func (m *managed) Update(float64) {}
+func (m *managed) Init()          { m.log.calls = append(m.log.calls, "init "+m.name) }
+func (m *managed) Shutdown()      { m.log.calls = append(m.log.calls, "shutdown "+m.name) }
+
+// plain implements neither Initializer nor Shutdowner
+type plain struct{}
+
+func (*plain) Update(float64) {}
+
+// byValue cannot be compared, it holds a slice
+type byValue struct{ state []int }
+
+func (byValue) Update(float64) {}
+
+func (l *lifecycle) take(t *testing.T, step string, want ...string) {
+	t.Helper()
+	if !slices.Equal(l.calls, want) {
+		t.Errorf("%s: %q, want %q", step, l.calls, want)
+	}
+	l.calls = nil
+}
+
+func TestSystemLifecycle(t *testing.T) {
+	log := &lifecycle{}
+	a, b, c := &managed{"a", log}, &managed{"b", log}, &managed{"c", log}
+
+	w := ecs.NewWorld()
+	w.AddSystems(a, &plain{}, b)
+	w.AddSystems(c)
+	log.take(t, "add", "init a", "init b", "init c")
+
+	// Systems that were never added are ignored, even of uncomparable types
+	w.AddSystems(byValue{})
+	w.RemoveSystems(&managed{"a", log}, &plain{}, byValue{})
+	log.take(t, "remove unknown")
+
+	w.RemoveSystems(b)
+	log.take(t, "remove", "shutdown b")
+	w.RemoveSystems(b)
+	log.take(t, "remove twice")
+
+	w.Update(0)
+	w.Close()
+	log.take(t, "close", "shutdown c", "shutdown a")
+	w.Close()
+	log.take(t, "close twice")
+}
@@ -0,0 +1,115 @@
+package input_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/internal/input"
+	"github.com/Salvadego/ECS/internal/systems"
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// run builds the demo simulation from a seed and feeds it frames of input,
+// returning its final binary snapshot
+func run(t *testing.T, seed int64, frames func(yield func(input.State) bool)) []byte {
+	t.Helper()
+	world := ecs.NewWorld()
+	movement := systems.NewMovementSystem(world, 600, 450)
+	world.AddSystems(movement, systems.NewTransformSystem(world), systems.NewInputSystem(world))
+
+	rng := rand.New(rand.NewSource(seed))
+	world.CreateEntities(200, func(_ int, row []ecs.Component) {
+		row[0] = &components.Position{X: float64(rng.Intn(600)), Y: float64(rng.Intn(450))}
+		row[1] = &components.Velocity{X: rng.Float64() * 100, Y: rng.Float64() * 100}
+	}, components.PositionID, components.VelocityID)
+
+	state := &input.State{}
+	ecs.SetResource(world, state)
+	for s := range frames {
+		*state = s
+		movement.SetSize(s.Width, s.Height)
+		world.Update(s.Dt)
+	}
+
+	var buf bytes.Buffer
+	if err := world.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRecordReplay(t *testing.T) {
+	const seed = 42
+
+	// Live input: the mouse sweeps across the screen, held every other second
+	live := func(yield func(input.State) bool) {
+		for frame := range 300 {
+			s := input.State{
+				Dt:           1.0/120 + float64(frame%3)/1000,
+				Width:        600,
+				Height:       450 - frame/10,
+				MouseX:       float64(frame * 2),
+				MouseY:       float64(frame),
+				MouseDown:    frame/120%2 == 1,
+				MousePressed: frame == 120,
+			}
+			if !yield(s) {
+				return
+			}
+		}
+	}
+
+	var recording bytes.Buffer
+	recorder, err := input.NewRecorder(&recording, input.Header{Seed: seed, Entities: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := run(t, seed, func(yield func(input.State) bool) {
+		for s := range live {
+			if err := recorder.Record(s); err != nil {
+				t.Fatal(err)
+			}
+			if !yield(s) {
+				return
+			}
+		}
+	})
+
+	player, err := input.NewPlayer(&recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if player.Header.Seed != seed {
+		t.Fatalf("seed = %d, want %d", player.Header.Seed, seed)
+	}
+	frames := 0
+	got := run(t, player.Header.Seed, func(yield func(input.State) bool) {
+		for {
+			s, err := player.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			frames++
+			if !yield(s) {
+				return
+			}
+		}
+	})
+
+	if frames != 300 {
+		t.Errorf("replayed %d frames, want 300", frames)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("replay diverged from the recorded run")
+	}
+	if other := run(t, seed+1, live); bytes.Equal(other, want) {
+		t.Error("a different seed produced the same world")
+	}
+}
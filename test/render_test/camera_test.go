@@ -0,0 +1,96 @@
+package render_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/internal/input"
+	"github.com/Salvadego/ECS/internal/render"
+	"github.com/Salvadego/ECS/internal/systems"
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCameraTransforms(t *testing.T) {
+	// NewCamera shows the world 1:1
+	identity := render.NewCamera(600, 450)
+	if x, y := identity.WorldToScreen(12, 34, 600, 450); !near(x, 12) || !near(y, 34) {
+		t.Errorf("NewCamera maps 12, 34 to %v, %v", x, y)
+	}
+
+	camera := &render.Camera{X: 100, Y: 50, Zoom: 2, Rotation: 90}
+	// The camera position is the center of the screen
+	if x, y := camera.WorldToScreen(100, 50, 200, 100); !near(x, 100) || !near(y, 50) {
+		t.Errorf("camera position maps to %v, %v, want the center of the screen", x, y)
+	}
+	// One world unit to the right is two pixels down once rotated clockwise
+	if x, y := camera.WorldToScreen(101, 50, 200, 100); !near(x, 100) || !near(y, 52) {
+		t.Errorf("101, 50 maps to %v, %v, want 100, 52", x, y)
+	}
+
+	for _, p := range [][2]float64{{0, 0}, {-30, 12.5}, {1e4, -7}} {
+		sx, sy := camera.WorldToScreen(p[0], p[1], 200, 100)
+		if x, y := camera.ScreenToWorld(sx, sy, 200, 100); !near(x, p[0]) || !near(y, p[1]) {
+			t.Errorf("%v round trips to %v, %v", p, x, y)
+		}
+	}
+}
+
+func TestRenderCamera(t *testing.T) {
+	world := ecs.NewWorld()
+	r := render.NewImage(32, 32)
+	world.AddSystems(systems.NewRenderSystem(world, r))
+	layeredScene(world)
+	// Zoomed out and rotated around the middle of the scene
+	ecs.SetResource(world, &render.Camera{X: 20, Y: 18, Zoom: 0.75, Rotation: -20})
+
+	world.Update(0)
+	r.Present()
+	assertGolden(t, "camera", r.Frame())
+}
+
+func TestCameraInput(t *testing.T) {
+	world := ecs.NewWorld()
+	world.AddSystems(systems.NewCameraSystem(world), systems.NewInputSystem(world))
+	camera := &render.Camera{X: 100, Y: 100, Zoom: 1}
+	ecs.SetResource(world, camera)
+	state := &input.State{Dt: 0.5, Width: 200, Height: 100}
+	ecs.SetResource(world, state)
+	clicks := ecs.GetEvents[systems.Clicked](world).Reader()
+
+	// Panning moves by screen pixels, so half as far in the world at zoom 2
+	camera.Zoom = 2
+	state.PanX = 1
+	world.Update(state.Dt)
+	if want := 100 + 300*0.5/2; !near(camera.X, want) || !near(camera.Y, 100) {
+		t.Errorf("camera at %v, %v after panning, want %v, 100", camera.X, camera.Y, want)
+	}
+
+	// Zooming keeps the world point under the mouse in place
+	*state = input.State{Dt: 0.5, Width: 200, Height: 100, MouseX: 150, MouseY: 20, Wheel: 3}
+	x, y := camera.ScreenToWorld(150, 20, 200, 100)
+	world.Update(state.Dt)
+	if !near(camera.Zoom, 2*1.1*1.1*1.1) {
+		t.Errorf("zoom = %v", camera.Zoom)
+	}
+	if nx, ny := camera.ScreenToWorld(150, 20, 200, 100); !near(nx, x) || !near(ny, y) {
+		t.Errorf("point under the mouse moved from %v, %v to %v, %v", x, y, nx, ny)
+	}
+
+	// Clicks and steering use world coordinates
+	entity := world.CreateEntity(&components.Position{X: x, Y: y + 10}, &components.Velocity{})
+	*state = input.State{Dt: 0.5, Width: 200, Height: 100, MouseX: 150, MouseY: 20, MouseDown: true, MousePressed: true}
+	world.Update(state.Dt)
+
+	got := clicks.Read()
+	if len(got) != 1 || !near(got[0].X, x) || !near(got[0].Y, y) {
+		t.Errorf("clicks = %v, want one at %v, %v", got, x, y)
+	}
+	if vel := ecs.GetComponent[*components.Velocity](world, entity); !near(vel.X, 0) || !near(vel.Y, -100) {
+		t.Errorf("velocity = %+v, want straight up toward the mouse", vel)
+	}
+}
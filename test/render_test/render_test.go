@@ -0,0 +1,191 @@
+package render_test
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/internal/render"
+	"github.com/Salvadego/ECS/internal/systems"
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden images")
+
+// assertGolden compares a frame against testdata/name.png, pixel by pixel
+func assertGolden(t *testing.T, name string, frame *image.RGBA) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".png")
+	if *update {
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := png.Encode(f, frame); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	defer f.Close()
+	golden, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if golden.Bounds() != frame.Bounds() {
+		t.Fatalf("frame is %v, golden image is %v", frame.Bounds(), golden.Bounds())
+	}
+
+	diff := 0
+	var first image.Point
+	bounds := frame.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if color.RGBAModel.Convert(golden.At(x, y)) != frame.RGBAAt(x, y) {
+				if diff == 0 {
+					first = image.Pt(x, y)
+				}
+				diff++
+			}
+		}
+	}
+	if diff > 0 {
+		t.Errorf("%d pixels differ from %s, first at %v: got %v, want %v",
+			diff, path, first, frame.RGBAAt(first.X, first.Y), golden.At(first.X, first.Y))
+	}
+}
+
+// checker is a sprite sheet of two 2x2 frames side by side: a white and blue
+// checkerboard, and a solid red frame
+func checker() *render.SpriteSheet {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := range 2 {
+		for x := range 2 {
+			if (x+y)%2 == 0 {
+				img.SetRGBA(x, y, color.RGBA{255, 255, 255, 255})
+			} else {
+				img.SetRGBA(x, y, color.RGBA{0, 0, 255, 255})
+			}
+			img.SetRGBA(x+2, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+	return render.NewSpriteSheet(render.NewSprite(img), 2, 2)
+}
+
+func TestImagePrimitives(t *testing.T) {
+	r := render.NewImage(32, 24)
+	r.Clear(color.RGBA{20, 20, 40, 255})
+	r.DrawRect(render.Quad{X: 8, Y: 6, Width: 12, Height: 8}, color.RGBA{255, 0, 0, 255})
+	r.DrawRect(render.Quad{X: 14, Y: 10, Width: 12, Height: 8}, color.RGBA{0, 255, 0, 128})
+	r.DrawRect(render.Quad{X: 33, Y: 25, Width: 10, Height: 10}, color.RGBA{255, 255, 0, 255})
+	r.DrawRect(render.Quad{X: 26, Y: 15, Width: 8, Height: 2, Rotation: 45}, color.RGBA{255, 0, 255, 255})
+	r.DrawCircle(5.5, 16.5, 3, color.RGBA{0, 200, 255, 200})
+	for i := range 10 {
+		r.DrawPoint(float64(2+i*3), 21.6, color.RGBA{255, 255, 255, uint8(25 * (i + 1))})
+	}
+	r.DrawPoint(-1, 5, color.RGBA{255, 255, 255, 255})
+
+	sheet := checker()
+	r.DrawSprite(sheet.Sprite, sheet.Frame(0), render.Quad{X: 25, Y: 3, Width: 2, Height: 2}, color.RGBA{255, 255, 255, 255})
+	r.DrawSprite(sheet.Sprite, sheet.Frame(0), render.Quad{X: 29, Y: 3, Width: 4, Height: 4}, color.RGBA{255, 128, 0, 255})
+	r.DrawSprite(sheet.Sprite, sheet.Sprite.Bounds(), render.Quad{X: 26, Y: 8, Width: 4, Height: 2}, color.RGBA{255, 255, 255, 128})
+
+	// Nothing is visible before Present
+	if r.Frame().RGBAAt(0, 0) != (color.RGBA{}) {
+		t.Fatal("frame drawn before Present")
+	}
+	r.Present()
+	assertGolden(t, "primitives", r.Frame())
+}
+
+func TestSpriteSheetFrames(t *testing.T) {
+	sheet := render.NewSpriteSheet(render.NewSprite(image.NewRGBA(image.Rect(0, 0, 10, 7))), 4, 3)
+	if n := sheet.Frames(); n != 4 {
+		t.Fatalf("Frames() = %d, want 4", n)
+	}
+	want := []image.Rectangle{
+		image.Rect(0, 0, 4, 3), image.Rect(4, 0, 8, 3),
+		image.Rect(0, 3, 4, 6), image.Rect(4, 3, 8, 6),
+	}
+	for i := -4; i < 8; i++ {
+		if got := sheet.Frame(i); got != want[(i+4)%4] {
+			t.Errorf("Frame(%d) = %v, want %v", i, got, want[(i+4)%4])
+		}
+	}
+}
+
+func TestRenderSystem(t *testing.T) {
+	world := ecs.NewWorld()
+	r := render.NewImage(16, 16)
+	world.AddSystems(systems.NewRenderSystem(world, r))
+
+	for i := range 8 {
+		world.CreateEntity(
+			&components.Position{X: float64(i*2) + 0.5, Y: float64(i*i) / 4},
+			&components.Renderable{Color: color.RGBA{R: uint8(i * 32), G: 255, B: 100, A: 255}},
+		)
+	}
+	// Without a Renderable it is not drawn
+	world.CreateEntity(&components.Position{X: 8, Y: 8})
+
+	world.Update(0)
+	r.Present()
+	assertGolden(t, "render_system", r.Frame())
+}
+
+// layeredScene creates shapes of every kind on several layers
+func layeredScene(world *ecs.World) {
+	ecs.SetResource(world, &systems.SpriteSheets{checker()})
+
+	// Created top layer first, so drawing in creation order would bury it
+	world.CreateEntity(
+		&components.Position{X: 16, Y: 16},
+		&components.Renderable{Shape: components.ShapeSprite, Width: 8, Height: 8, Rotation: 30, Layer: 2,
+			Color: color.RGBA{255, 255, 255, 255}},
+	)
+	world.CreateEntity(
+		&components.Position{X: 12, Y: 12},
+		&components.Renderable{Shape: components.ShapeCircle, Width: 14, Layer: 1,
+			Color: color.RGBA{255, 200, 0, 255}},
+	)
+	world.CreateEntity(
+		&components.Position{X: 16, Y: 16},
+		&components.Renderable{Shape: components.ShapeRect, Width: 28, Height: 20, Layer: -1,
+			Color: color.RGBA{60, 60, 160, 255}},
+	)
+	world.CreateEntity(
+		&components.Position{X: 24, Y: 24},
+		&components.Renderable{Shape: components.ShapeSprite, Width: 6, Height: 6, Frame: 3, Layer: 1,
+			Color: color.RGBA{255, 255, 255, 192}},
+	)
+	// Points keep working, and a missing sheet draws nothing
+	world.CreateEntity(
+		&components.Position{X: 2, Y: 2},
+		&components.Renderable{Color: color.RGBA{255, 255, 255, 255}, Layer: 5},
+	)
+	world.CreateEntity(
+		&components.Position{X: 4, Y: 28},
+		&components.Renderable{Shape: components.ShapeSprite, Width: 4, Height: 4, Sheet: 1},
+	)
+}
+
+func TestRenderLayers(t *testing.T) {
+	world := ecs.NewWorld()
+	r := render.NewImage(32, 32)
+	world.AddSystems(systems.NewRenderSystem(world, r))
+	layeredScene(world)
+
+	world.Update(0)
+	r.Present()
+	assertGolden(t, "layers", r.Frame())
+}
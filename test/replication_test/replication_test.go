@@ -0,0 +1,219 @@
+package replication_test
+
+import (
+	"encoding/json"
+	"image/color"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/internal/systems"
+	"github.com/Salvadego/ECS/pkg/ecs"
+	"github.com/Salvadego/ECS/pkg/replication"
+)
+
+func init() {
+	replication.Replicate(components.PositionID, components.RenderableID)
+}
+
+func serverWorld() (*ecs.World, *replication.Server) {
+	w := ecs.NewWorld()
+	w.CreateEntities(20, func(i int, row []ecs.Component) {
+		row[0] = &components.Position{X: float64(i * 5), Y: float64(i * 3)}
+		row[1] = &components.Renderable{Color: color.RGBA{G: 255, A: uint8(i)}}
+		row[2] = &components.Velocity{X: 30, Y: -20}
+	}, components.PositionID, components.RenderableID, components.VelocityID)
+	// Not replicated: no Position nor Renderable
+	w.CreateEntity(&components.Velocity{X: 1})
+
+	server := replication.NewServer(w)
+	w.AddSystems(systems.NewMovementSystem(w, 100, 100), server)
+	return w, server
+}
+
+// assertReplicated checks that client holds exactly the replicated
+// components of the server entities
+func assertReplicated(t *testing.T, frame int, server, client *ecs.World, c *replication.Client) {
+	t.Helper()
+	filter := ecs.NewFilter()
+	filter.Optional(components.PositionID, components.RenderableID)
+	want := 0
+	it := filter.Iterator(server)
+	for it.Next() {
+		entity := it.Entity()
+		row := it.Row()
+		if row[0] == nil && row[1] == nil {
+			continue
+		}
+		want++
+
+		local, ok := c.Local(entity)
+		if !ok {
+			t.Fatalf("frame %d: entity %d not replicated", frame, entity)
+		}
+		got, _ := client.Components(local)
+		var expected []ecs.Component
+		for _, comp := range row {
+			if comp != nil {
+				expected = append(expected, comp)
+			}
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("frame %d: entity %d: got %v, want %v", frame, entity, got, expected)
+		}
+	}
+
+	mapped := 0
+	for it := filter.Iterator(client); it.Next(); {
+		if _, ok := c.Remote(it.Entity()); ok {
+			mapped++
+		}
+	}
+	if mapped != want {
+		t.Fatalf("frame %d: client has %d replicated entities, want %d", frame, mapped, want)
+	}
+}
+
+func TestReplicationOverPipe(t *testing.T) {
+	w, server := serverWorld()
+	serverConn, clientConn := net.Pipe()
+	server.Accept(serverConn)
+
+	clientWorld := ecs.NewWorld()
+	// A client side entity shifts the IDs of replicated ones
+	clientWorld.CreateEntity(&components.Position{X: -1, Y: -1})
+	client := replication.NewClient(clientWorld, clientConn)
+
+	for frame := 1; frame <= 30; frame++ {
+		switch frame {
+		case 10:
+			w.DestroyEntity(3)
+			w.RemoveComponents(4, components.RenderableID)
+		case 20:
+			w.CreateEntity(&components.Renderable{Color: color.RGBA{R: 255, A: 255}})
+			w.AddComponents(20, &components.Position{X: 50, Y: 50})
+		}
+		w.Update(1.0 / 60)
+
+		if err := client.Receive(); err != nil {
+			t.Fatalf("frame %d: %v", frame, err)
+		}
+		assertReplicated(t, frame, w, clientWorld, client)
+	}
+
+	if _, ok := client.Local(3); ok {
+		t.Error("destroyed entity still mapped")
+	}
+	if local, _ := client.Local(0); local == 0 {
+		t.Error("server entity 0 mapped onto the client entity")
+	}
+
+	server.Shutdown()
+	if err := client.Receive(); err == nil {
+		t.Error("Receive succeeded on a closed connection")
+	}
+	if err := server.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClientSystem(t *testing.T) {
+	w, server := serverWorld()
+	serverConn, clientConn := net.Pipe()
+	server.Accept(serverConn)
+
+	clientWorld := ecs.NewWorld()
+	client := replication.NewClient(clientWorld, clientConn)
+	clientWorld.AddSystems(client)
+	defer clientWorld.Close()
+
+	w.Update(1.0 / 60)
+	deadline := time.Now().Add(time.Second)
+	for len(ecs.NewFilter(components.PositionID).Query(clientWorld)) != 20 {
+		if time.Now().After(deadline) {
+			t.Fatal("client did not receive the initial state")
+		}
+		time.Sleep(time.Millisecond)
+		clientWorld.Update(0)
+	}
+	if server.Clients() != 1 {
+		t.Errorf("clients = %d, want 1", server.Clients())
+	}
+}
+
+func TestPairsNotReplicated(t *testing.T) {
+	defer func() {
+		if msg, _ := recover().(string); !strings.Contains(msg, "pairs are not replicated") {
+			t.Errorf("Replicate of a pair ID panicked with %q", msg)
+		}
+	}()
+
+	// A delta holding a pair, as a server replicating everything would send
+	w := ecs.NewWorld()
+	parent := w.CreateEntity(&components.Position{X: 1})
+	child := w.CreateEntity(&components.Position{X: 2})
+	w.SetParent(child, parent)
+	data, err := json.Marshal(ecs.Diff(nil, w))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	go serverConn.Write(data)
+
+	clientWorld := ecs.NewWorld()
+	client := replication.NewClient(clientWorld, clientConn)
+	if err := client.Receive(); err != nil {
+		t.Fatal(err)
+	}
+	local, ok := client.Local(child)
+	if !ok {
+		t.Fatal("child not replicated")
+	}
+	got, _ := clientWorld.Components(local)
+	if want := []ecs.Component{&components.Position{X: 2}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("child components = %v, want %v", got, want)
+	}
+
+	replication.Replicate(w.PairID(ecs.ChildOf, parent))
+}
+
+func TestLateClient(t *testing.T) {
+	w, server := serverWorld()
+	for range 5 {
+		w.Update(1.0 / 60)
+	}
+
+	serverConn, clientConn := net.Pipe()
+	server.Accept(serverConn)
+	clientWorld := ecs.NewWorld()
+	client := replication.NewClient(clientWorld, clientConn)
+
+	// A client joining late gets the full state, then the following deltas,
+	// while another goroutine looks entities up
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 1000 {
+			client.Local(0)
+			client.Remote(0)
+		}
+	}()
+	for frame := 1; frame <= 5; frame++ {
+		w.Update(1.0 / 60)
+		if err := client.Receive(); err != nil {
+			t.Fatalf("frame %d: %v", frame, err)
+		}
+		assertReplicated(t, frame, w, clientWorld, client)
+	}
+	<-done
+
+	server.Shutdown()
+	if err := server.Err(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,70 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/internal/systems"
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+func TestDeltaReplicatesDemoFrames(t *testing.T) {
+	const width, height = 200, 100
+	w := particles(50)
+	parent := w.CreateEntity(
+		&components.LocalPosition{X: 20, Y: 20},
+		&components.GlobalPosition{},
+	)
+	w.AddSystems(systems.NewMovementSystem(w, width, height), systems.NewTransformSystem(w))
+
+	replica := ecs.NewWorld()
+	prev := w.Snapshot()
+	replica.ApplyDelta(ecs.Diff(nil, w))
+	assertSameWorld(t, 0, w, replica)
+
+	var children []ecs.EntityID
+	for frame := 1; frame <= 60; frame++ {
+		switch {
+		case frame%10 == 0:
+			child := w.CreateEntity(&components.LocalPosition{X: float64(frame)}, &components.GlobalPosition{})
+			w.SetParent(child, parent)
+			children = append(children, child)
+		case frame%15 == 0:
+			w.DestroyEntity(ecs.EntityID(frame))
+			w.RemoveComponents(ecs.EntityID(frame+1), components.VelocityID)
+		case frame == 33:
+			w.RemoveParent(children[0])
+			w.AddComponents(children[1], &components.Velocity{X: 1})
+		}
+		w.Update(1.0 / 60)
+
+		delta := ecs.Diff(prev, w)
+		if delta.Empty() {
+			t.Fatalf("frame %d: moving particles produced an empty delta", frame)
+		}
+		replica.ApplyDelta(delta)
+		prev = w.Snapshot()
+		assertSameWorld(t, frame, w, replica)
+	}
+
+	if delta := ecs.Diff(prev, w); !delta.Empty() {
+		t.Errorf("delta without changes: %+v", delta)
+	}
+}
+
+func assertSameWorld(t *testing.T, frame int, want, got *ecs.World) {
+	t.Helper()
+	a, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Fatalf("frame %d: replica differs:\n%s\n%s", frame, a, b)
+	}
+}
@@ -0,0 +1,130 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// Mover went through three layouts, the fixtures in testdata were saved with
+// the older ones:
+//   - version 0: float32 DX, DY
+//   - version 1: DX, DY renamed to float64 VX, VY
+//   - version 2: velocity split into a unit direction and a speed
+type Mover struct {
+	Dir   components.Vector2
+	Speed float64
+}
+
+const MoverID ecs.ComponentID = 64
+
+func (m Mover) ID() ecs.ComponentID { return MoverID }
+
+type moverV0 struct{ DX, DY float32 }
+
+type moverV1 struct{ VX, VY float64 }
+
+func init() {
+	ecs.RegisterComponentType[Mover](MoverID)
+	ecs.RegisterComponentVersion(MoverID, 2)
+	ecs.RegisterMigration(MoverID, 0, func(m moverV0) moverV1 {
+		return moverV1{VX: float64(m.DX), VY: float64(m.DY)}
+	})
+	ecs.RegisterMigration(MoverID, 1, func(m moverV1) Mover {
+		speed := math.Hypot(m.VX, m.VY)
+		if speed == 0 {
+			return Mover{}
+		}
+		return Mover{Dir: components.Vector2{X: m.VX / speed, Y: m.VY / speed}, Speed: speed}
+	})
+}
+
+func loadFixture(t *testing.T, name string) *ecs.World {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var w *ecs.World
+	if strings.HasSuffix(name, ".json") {
+		w, err = ecs.LoadWorld(data)
+	} else {
+		w, err = ecs.ReadSnapshot(bytes.NewReader(data))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestMigrations(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []Mover
+	}{
+		{"mover_v0.json", []Mover{{components.Vector2{X: 0.6, Y: -0.8}, 2.5}, {components.Vector2{X: 0.6, Y: 0.8}, 5}}},
+		{"mover_v0.bin", []Mover{{components.Vector2{X: 0.6, Y: -0.8}, 2.5}, {components.Vector2{X: 0.6, Y: 0.8}, 5}}},
+		{"mover_v1.json", []Mover{{components.Vector2{X: 0.6, Y: 0.8}, 5}, {components.Vector2{X: 0, Y: -1}, 0.5}}},
+		{"mover_v1.bin", []Mover{{components.Vector2{X: 0.6, Y: 0.8}, 5}, {components.Vector2{X: 0, Y: -1}, 0.5}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			w := loadFixture(t, tt.fixture)
+			for i, want := range tt.want {
+				got := ecs.GetComponent[Mover](w, ecs.EntityID(i))
+				if !closeTo(got, want) {
+					t.Errorf("entity %d: got %+v, want %+v", i, got, want)
+				}
+			}
+
+			pos := ecs.GetComponent[*components.Position](w, 0)
+			if pos == nil || *pos != (components.Position{X: 10, Y: 20}) {
+				t.Errorf("position = %v, want {10 20}", pos)
+			}
+			if rend := ecs.GetComponent[*components.Renderable](w, 1); rend == nil || rend.Color.A != 4 {
+				t.Errorf("renderable = %v", rend)
+			}
+		})
+	}
+}
+
+func TestMigratedSnapshotSavesCurrentVersion(t *testing.T) {
+	w := loadFixture(t, "mover_v0.json")
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct {
+		Versions map[string]uint32 `json:"versions"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if v := saved.Versions["snapshot_test.Mover"]; v != 2 {
+		t.Errorf("snapshot records Mover version %d, want 2: %s", v, data)
+	}
+
+	loaded, err := ecs.LoadWorld(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ecs.GetComponent[Mover](loaded, 0), ecs.GetComponent[Mover](w, 0); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func closeTo(a, b Mover) bool {
+	const eps = 1e-6
+	return math.Abs(a.Dir.X-b.Dir.X) < eps &&
+		math.Abs(a.Dir.Y-b.Dir.Y) < eps &&
+		math.Abs(a.Speed-b.Speed) < eps
+}
@@ -0,0 +1,38 @@
+package snapshot_test
+
+import (
+	"encoding/json"
+	"image/color"
+	"testing"
+
+	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// Renderable only had a Color before shapes came in version 1
+func TestRenderableMigration(t *testing.T) {
+	data := []byte(`{"nextEntity":1,"entities":[{"id":0,"components":{"components.Renderable":{"Color":{"R":1,"G":2,"B":3,"A":4}}}}]}`)
+	w, err := ecs.LoadWorld(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rend := ecs.GetComponent[*components.Renderable](w, 0)
+	if rend == nil || *rend != (components.Renderable{Color: color.RGBA{1, 2, 3, 4}, Shape: components.ShapePoint}) {
+		t.Fatalf("renderable = %v", rend)
+	}
+
+	saved, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot struct {
+		Versions map[string]uint32 `json:"versions"`
+	}
+	if err := json.Unmarshal(saved, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if v := snapshot.Versions["components.Renderable"]; v != 1 {
+		t.Errorf("snapshot records Renderable version %d, want 1: %s", v, saved)
+	}
+}
@@ -0,0 +1,111 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/internal/systems"
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// simulation is the demo world driven by per frame inputs
+type simulation struct {
+	world  *ecs.World
+	parent ecs.EntityID
+}
+
+func newSimulation() *simulation {
+	w := particles(40)
+	parent := w.CreateEntity(
+		&components.Position{X: 50, Y: 50},
+		&components.Velocity{X: 10, Y: 5},
+	)
+	w.AddSystems(systems.NewMovementSystem(w, 200, 100), systems.NewTransformSystem(w))
+	return &simulation{world: w, parent: parent}
+}
+
+// step applies the input of a frame then updates the world. input stands for
+// what players did: spawn a child, push a particle or destroy one.
+func (s *simulation) step(frame, input int) {
+	w := s.world
+	switch input % 4 {
+	case 1:
+		child := w.CreateEntity(
+			&components.LocalPosition{X: float64(frame), Y: 1},
+			&components.GlobalPosition{},
+		)
+		w.SetParent(child, s.parent)
+	case 2:
+		if vel := ecs.GetComponentMut[*components.Velocity](w, ecs.EntityID(input%40)); vel != nil {
+			vel.X += float64(frame)
+		}
+	case 3:
+		w.DestroyEntity(ecs.EntityID(input % 40))
+	}
+	w.Update(1.0 / 60)
+}
+
+func checksum(t *testing.T, w *ecs.World) [32]byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := w.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return sha256.Sum256(buf.Bytes())
+}
+
+func inputs(frame int) int { return frame * 7 % 13 }
+
+func TestRollbackResimulation(t *testing.T) {
+	const frames, rewind = 120, 40
+
+	reference := newSimulation()
+	want := make([][32]byte, frames+1)
+	want[0] = checksum(t, reference.world)
+	for frame := range frames {
+		reference.step(frame, inputs(frame))
+		want[frame+1] = checksum(t, reference.world)
+	}
+
+	sim := newSimulation()
+	sim.world.EnableRollback(30)
+	if got := checksum(t, sim.world); got != want[0] {
+		t.Fatal("initial states differ")
+	}
+
+	// Predict wrong inputs for a while, then rewind and resimulate with the
+	// inputs that actually happened, as rollback netcode does.
+	rewound := false
+	for frame := 0; frame < frames; {
+		input := inputs(frame)
+		if !rewound && frame >= rewind {
+			input = frame
+		}
+		sim.step(frame, input)
+		frame++
+
+		if !rewound && frame == rewind+20 {
+			rewound = true
+			if !sim.world.Rollback(rewind) {
+				t.Fatalf("no capture of tick %d", rewind)
+			}
+			if got := sim.world.Tick(); got != rewind {
+				t.Fatalf("tick after rollback = %d, want %d", got, rewind)
+			}
+			if got := checksum(t, sim.world); got != want[rewind] {
+				t.Fatalf("rolled back state differs from tick %d", rewind)
+			}
+			frame = rewind
+			continue
+		}
+		if got := checksum(t, sim.world); got != want[frame] && (rewound || frame < rewind) {
+			t.Fatalf("frame %d: runs diverged", frame)
+		}
+	}
+
+	if sim.world.Rollback(10) {
+		t.Error("rolled back past the captured frames")
+	}
+}
@@ -0,0 +1,232 @@
+package snapshot_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image/color"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// demoWorld builds a world with every demo component and a small hierarchy
+func demoWorld() (*ecs.World, []ecs.EntityID) {
+	w := ecs.NewWorld()
+	particle := w.CreateEntity(
+		&components.Position{X: 10, Y: 20},
+		&components.Velocity{X: -1.5, Y: 2.25},
+		&components.Renderable{Color: color.RGBA{R: 100, G: 255, B: 100, A: 180}},
+	)
+	still := w.CreateEntity(&components.Position{X: 3, Y: 4})
+	parent := w.CreateEntity(
+		&components.LocalPosition{X: 100, Y: 100},
+		&components.GlobalPosition{X: 100, Y: 100},
+	)
+	child := w.CreateEntity(
+		&components.LocalPosition{X: 5, Y: -5},
+		&components.GlobalPosition{X: 105, Y: 95},
+		&components.Renderable{Color: color.RGBA{R: 255, A: 255}},
+	)
+	w.SetParent(child, parent)
+	w.DestroyEntity(still)
+
+	return w, []ecs.EntityID{particle, parent, child}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	w, entities := demoWorld()
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ecs.LoadWorld(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entity := range entities {
+		want, _ := w.Components(entity)
+		got, ok := loaded.Components(entity)
+		if !ok {
+			t.Fatalf("entity %d missing after load", entity)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("entity %d: got %v, want %v", entity, got, want)
+		}
+	}
+	if loaded.Alive(entities[1] - 1) {
+		t.Error("destroyed entity restored")
+	}
+
+	parent, ok := loaded.Parent(entities[2])
+	if !ok || parent != entities[1] {
+		t.Errorf("parent = %d, %v, want %d", parent, ok, entities[1])
+	}
+
+	moving := ecs.NewFilter(components.PositionID, components.VelocityID, components.RenderableID)
+	if n := len(moving.Query(loaded)); n != 1 {
+		t.Errorf("moving entities = %d, want 1", n)
+	}
+
+	again, err := json.Marshal(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("snapshot changed after round trip:\n%s\n%s", data, again)
+	}
+
+	if next := loaded.CreateEntity(&components.Position{}); next != entities[2]+1 {
+		t.Errorf("new entity = %d, want %d", next, entities[2]+1)
+	}
+}
+
+func TestLoadWorldUnknownComponent(t *testing.T) {
+	data := []byte(`{"nextEntity":1,"entities":[{"id":0,"components":{"components.Missing":{}}}]}`)
+	if _, err := ecs.LoadWorld(data); err == nil {
+		t.Error("expected an error for an unknown component type")
+	}
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	w, entities := demoWorld()
+	var buf bytes.Buffer
+	if err := w.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := ecs.ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entity := range entities {
+		want, _ := w.Components(entity)
+		got, ok := loaded.Components(entity)
+		if !ok {
+			t.Fatalf("entity %d missing after load", entity)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("entity %d: got %v, want %v", entity, got, want)
+		}
+	}
+
+	parent, ok := loaded.Parent(entities[2])
+	if !ok || parent != entities[1] {
+		t.Errorf("parent = %d, %v, want %d", parent, ok, entities[1])
+	}
+
+	want, _ := json.Marshal(w)
+	got, _ := json.Marshal(loaded)
+	if !bytes.Equal(got, want) {
+		t.Errorf("binary round trip differs from JSON:\n%s\n%s", want, got)
+	}
+}
+
+func TestBinaryChecksum(t *testing.T) {
+	w, _ := demoWorld()
+	var buf bytes.Buffer
+	if err := w.WriteSnapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	data[len(data)-10] ^= 0xff
+	if _, err := ecs.ReadSnapshot(bytes.NewReader(data)); !errors.Is(err, ecs.ErrChecksum) {
+		t.Errorf("err = %v, want %v", err, ecs.ErrChecksum)
+	}
+}
+
+// Counts come before the checksum in the stream; a corrupted count must be
+// reported rather than allocated.
+func TestBinaryCorruptedCounts(t *testing.T) {
+	header := func(counts ...uint32) []byte {
+		data := []byte("ECSS")
+		data = binary.LittleEndian.AppendUint16(data, ecs.SnapshotVersion)
+		data = binary.LittleEndian.AppendUint64(data, 0)
+		for _, n := range counts {
+			data = binary.LittleEndian.AppendUint32(data, n)
+		}
+		return data
+	}
+	withCRC := func(data []byte) []byte {
+		return binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+	}
+
+	// 22 bytes claiming 0xFFFFFFFF schema entries, with a wrong checksum
+	corrupted := binary.LittleEndian.AppendUint32(header(0xFFFFFFFF), 0)
+	if _, err := ecs.ReadSnapshot(bytes.NewReader(corrupted)); !errors.Is(err, ecs.ErrChecksum) {
+		t.Errorf("err = %v, want %v", err, ecs.ErrChecksum)
+	}
+
+	// Matching checksums still cannot make the reader allocate past the input
+	for name, data := range map[string][]byte{
+		"schema":     withCRC(header(0xFFFFFFFF)),
+		"archetypes": withCRC(header(0, 0xFFFFFFFF)),
+		"entities":   withCRC(header(0, 1, 0xFFFFFFFF)),
+	} {
+		if _, err := ecs.ReadSnapshot(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+// particles builds a world like the demo with n moving particles
+func particles(n int) *ecs.World {
+	w := ecs.NewWorld()
+	w.CreateEntities(n, func(i int, row []ecs.Component) {
+		row[0] = &components.Position{X: float64(i), Y: float64(i * 2)}
+		row[1] = &components.Renderable{Color: color.RGBA{R: 100, G: 255, B: 100, A: uint8(i)}}
+		row[2] = &components.Velocity{X: 1, Y: -1}
+	}, components.PositionID, components.RenderableID, components.VelocityID)
+	return w
+}
+
+func BenchmarkSnapshot(b *testing.B) {
+	for _, n := range []int{1000, 100000} {
+		w := particles(n)
+
+		b.Run(fmt.Sprintf("JSON_Save_%d", n), func(b *testing.B) {
+			for b.Loop() {
+				if _, err := json.Marshal(w); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		data, _ := json.Marshal(w)
+		b.Run(fmt.Sprintf("JSON_Load_%d", n), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			for b.Loop() {
+				if _, err := ecs.LoadWorld(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Binary_Save_%d", n), func(b *testing.B) {
+			for b.Loop() {
+				if err := w.WriteSnapshot(io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		var buf bytes.Buffer
+		w.WriteSnapshot(&buf)
+		b.Run(fmt.Sprintf("Binary_Load_%d", n), func(b *testing.B) {
+			b.SetBytes(int64(buf.Len()))
+			for b.Loop() {
+				if _, err := ecs.ReadSnapshot(bytes.NewReader(buf.Bytes())); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package systems_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Salvadego/ECS/internal/components"
+	"github.com/Salvadego/ECS/internal/systems"
+	"github.com/Salvadego/ECS/pkg/ecs"
+)
+
+// recomputed records, each frame, the entities whose GlobalPosition the
+// transform system running before it wrote
+type recomputed struct {
+	world  *ecs.World
+	frames [][]ecs.EntityID
+}
+
+func (r *recomputed) Update(_ float64) {
+	filter := ecs.NewFilter(components.GlobalPositionID)
+	it := filter.Changed(components.GlobalPositionID).Iterator(r.world)
+	var entities []ecs.EntityID
+	for it.Next() {
+		entities = append(entities, it.Entity())
+	}
+	slices.Sort(entities)
+	r.frames = append(r.frames, entities)
+}
+
+func (r *recomputed) last() []ecs.EntityID {
+	return r.frames[len(r.frames)-1]
+}
+
+func global(t *testing.T, w *ecs.World, entity ecs.EntityID) components.Vector2 {
+	t.Helper()
+	gp := ecs.GetComponent[*components.GlobalPosition](w, entity)
+	if gp == nil {
+		t.Fatalf("entity %d has no GlobalPosition", entity)
+	}
+	return components.Vector2(*gp)
+}
+
+func TestTransformPropagation(t *testing.T) {
+	w := ecs.NewWorld()
+	watcher := &recomputed{world: w}
+	w.AddSystems(systems.NewTransformSystem(w), watcher)
+
+	root := w.CreateEntity(&components.Position{X: 10, Y: 20})
+	child := w.CreateEntity(&components.LocalPosition{X: 1, Y: 2})
+	grandchild := w.CreateEntity(&components.LocalPosition{X: 3, Y: 4})
+	other := w.CreateEntity(&components.LocalPosition{X: 5, Y: 5})
+	w.SetParent(child, root)
+	w.SetParent(grandchild, child)
+	w.SetParent(other, root)
+
+	w.Update(0)
+	want := map[ecs.EntityID]components.Vector2{
+		child:      {X: 11, Y: 22},
+		grandchild: {X: 14, Y: 26},
+		other:      {X: 15, Y: 25},
+	}
+	for entity, pos := range want {
+		if got := global(t, w, entity); got != pos {
+			t.Errorf("entity %d: global position %v, want %v", entity, got, pos)
+		}
+	}
+	if got, want := watcher.last(), []ecs.EntityID{child, grandchild, other}; !slices.Equal(got, want) {
+		t.Errorf("first frame recomputed %v, want %v", got, want)
+	}
+
+	// Only the subtree below the moved child is recomputed
+	ecs.GetComponentMut[*components.LocalPosition](w, child).X = 2
+	w.Update(0)
+	if got, want := watcher.last(), []ecs.EntityID{child, grandchild}; !slices.Equal(got, want) {
+		t.Errorf("after moving the child recomputed %v, want %v", got, want)
+	}
+	if got, want := global(t, w, grandchild), (components.Vector2{X: 15, Y: 26}); got != want {
+		t.Errorf("grandchild global position %v, want %v", got, want)
+	}
+
+	w.Update(0)
+	if got := watcher.last(); got != nil {
+		t.Errorf("idle frame recomputed %v", got)
+	}
+
+	// Moving the root moves the whole tree
+	ecs.GetComponentMut[*components.Position](w, root).Y = 0
+	w.Update(0)
+	if got, want := watcher.last(), []ecs.EntityID{child, grandchild, other}; !slices.Equal(got, want) {
+		t.Errorf("after moving the root recomputed %v, want %v", got, want)
+	}
+	if got, want := global(t, w, grandchild), (components.Vector2{X: 15, Y: 6}); got != want {
+		t.Errorf("grandchild global position %v, want %v", got, want)
+	}
+}
+
+func TestTransformReparent(t *testing.T) {
+	w := ecs.NewWorld()
+	watcher := &recomputed{world: w}
+	w.AddSystems(systems.NewTransformSystem(w), watcher)
+
+	a := w.CreateEntity(&components.Position{X: 10, Y: 10})
+	b := w.CreateEntity(&components.Position{X: 100, Y: 100})
+	child := w.CreateEntity(&components.LocalPosition{X: 1, Y: 2})
+	grandchild := w.CreateEntity(&components.LocalPosition{X: 3, Y: 4})
+	w.SetParent(child, a)
+	w.SetParent(grandchild, child)
+	w.Update(0)
+
+	// Reparenting alone, without touching LocalPosition, moves the subtree
+	w.SetParent(child, b)
+	w.Update(0)
+	if got, want := watcher.last(), []ecs.EntityID{child, grandchild}; !slices.Equal(got, want) {
+		t.Errorf("after reparenting recomputed %v, want %v", got, want)
+	}
+	if got, want := global(t, w, child), (components.Vector2{X: 101, Y: 102}); got != want {
+		t.Errorf("child global position %v, want %v", got, want)
+	}
+	if got, want := global(t, w, grandchild), (components.Vector2{X: 104, Y: 106}); got != want {
+		t.Errorf("grandchild global position %v, want %v", got, want)
+	}
+
+	// A detached child is positioned relative to the origin
+	w.RemoveParent(child)
+	w.Update(0)
+	if got, want := watcher.last(), []ecs.EntityID{child, grandchild}; !slices.Equal(got, want) {
+		t.Errorf("after detaching recomputed %v, want %v", got, want)
+	}
+	if got, want := global(t, w, grandchild), (components.Vector2{X: 4, Y: 6}); got != want {
+		t.Errorf("grandchild global position %v, want %v", got, want)
+	}
+
+	// Attaching a root is picked up as well
+	w.SetParent(child, a)
+	w.Update(0)
+	if got, want := global(t, w, grandchild), (components.Vector2{X: 14, Y: 16}); got != want {
+		t.Errorf("grandchild global position %v, want %v", got, want)
+	}
+}
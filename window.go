@@ -0,0 +1,63 @@
+//go:build !headless
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Salvadego/ECS/internal/input"
+	"github.com/Salvadego/ECS/internal/systems"
+	"github.com/Salvadego/ECS/internal/window"
+	rl "github.com/gen2brain/raylib-go/raylib"
+)
+
+// run opens the window and runs the simulation with rendering until the
+// window is closed, recording the input to recordFile when set
+func run(seed int64, entities, profileFrames int, recordFile string) error {
+	var recorder *input.Recorder
+	if recordFile != "" {
+		f, err := os.Create(recordFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		recorder, err = input.NewRecorder(f, input.Header{Seed: seed, Entities: entities})
+		if err != nil {
+			return err
+		}
+	}
+
+	win := window.Open(screenWidth, screenHeight, "ECS")
+	defer win.Close()
+
+	sim := newSimulation(seed, entities, profileFrames)
+	sim.world.AddSystems(systems.NewRenderSystem(sim.world, win))
+
+	for !win.ShouldClose() {
+		in := win.Input()
+		if recorder != nil {
+			if err := recorder.Record(in); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				recorder = nil
+			}
+		}
+
+		sim.step(in)
+
+		rl.DrawFPS(10, 10)
+		rl.DrawText(fmt.Sprintf("Entity count: %d", entities), 10, 30, 20, rl.White)
+
+		win.Present()
+	}
+
+	if profileFrames > 0 {
+		fmt.Print(sim.world.Stats())
+	}
+	if recorder != nil {
+		fmt.Printf("seed: %d, checksum: %016x\n", seed, sim.world.Checksum())
+	}
+
+	sim.world.Close()
+	return nil
+}